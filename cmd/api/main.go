@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"gin-server-template/internal/app"
 	"gin-server-template/internal/config"
+	"gin-server-template/pkg/logger"
+	"gin-server-template/pkg/tracing"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -16,6 +21,37 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// 初始化日志
+	logCloser, err := logger.Setup(logger.Options{
+		Level:  cfg.Log.Level,
+		Format: cfg.Log.Format,
+		Output: cfg.Log.Output,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logCloser.Close()
+
+	// 初始化链路追踪
+	shutdownTracing, err := tracing.Setup(tracing.Options{
+		Enabled:     cfg.Tracing.Enabled,
+		ServiceName: cfg.Tracing.ServiceName,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		// 退出前导出尚未发送的span
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("关闭链路追踪失败", "error", err)
+		}
+	}()
+
 	// 初始化服务器
 	server := app.NewServer(cfg)
 
@@ -26,16 +62,19 @@ func main() {
 	// 在单独的goroutine中启动服务器
 	go func() {
 		if err := server.Run(); err != nil {
-			log.Printf("服务器停止运行: %v", err)
+			slog.Error("服务器停止运行", "error", err)
 			sigChan <- syscall.SIGTERM // 如果服务器异常停止，发送信号以触发清理
 		}
 	}()
 
 	// 等待终止信号
 	sig := <-sigChan
-	log.Printf("接收到信号: %v，准备关闭服务器", sig)
+	slog.Info("接收到信号，准备关闭服务器", "signal", sig.String())
 
-	// 关闭服务器并释放资源
+	// 优雅关闭服务器，等待处理中的请求完成后再释放资源
+	if err := server.Shutdown(); err != nil {
+		slog.Error("优雅关闭服务器失败", "error", err)
+	}
 	server.Close()
-	log.Println("服务器已安全关闭")
+	slog.Info("服务器已安全关闭")
 }
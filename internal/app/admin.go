@@ -0,0 +1,89 @@
+package app
+
+import (
+	"errors"
+	"expvar"
+	"gin-server-template/internal/controller"
+	"gin-server-template/internal/middleware"
+	"gin-server-template/pkg/response"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newAdminServer 创建管理端口的HTTP服务器，未配置端口或令牌时返回nil
+//
+// 管理端口提供pprof、expvar、构建信息和脱敏后的配置，所有接口都需要管理令牌。
+func (s *Server) newAdminServer() *http.Server {
+	cfg := s.config.Server
+	if cfg.AdminPort == 0 {
+		return nil
+	}
+	if cfg.AdminToken == "" {
+		slog.Warn("未配置管理令牌，管理端口不会启动")
+		return nil
+	}
+
+	router := gin.New()
+	// 审计中间件需要在ErrorHandler之前，才能拿到最终的响应状态码
+	router.Use(middleware.RequestID(), middleware.Logger(), middleware.AdminAudit(), response.ErrorHandler(response.ErrorOptions{
+		Format:          s.config.Response.ErrorFormat,
+		ProblemTypeBase: s.config.Response.ProblemTypeBase,
+	}), middleware.Recovery())
+	router.Use(middleware.AdminAuth(cfg.AdminToken))
+
+	adminController := controller.NewAdminController(s.config)
+	admin := router.Group("/admin")
+	{
+		admin.GET("/buildinfo", adminController.BuildInfo)
+		admin.GET("/config", adminController.Config)
+		admin.GET("/audit-events", adminController.ListAuditEvents)
+
+		// OAuth2客户端由管理员注册，普通用户无法自行登记客户端
+		admin.POST("/oauth/clients", middleware.NoStore(), middleware.RequireJSON(), controller.NewOAuthController().RegisterClient)
+	}
+
+	// 显式注册pprof处理器，避免使用http.DefaultServeMux将其暴露到其他端口
+	debug := router.Group("/debug")
+	{
+		debug.GET("/vars", gin.WrapH(expvar.Handler()))
+		debug.GET("/pprof/", gin.WrapF(pprof.Index))
+		debug.GET("/pprof/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/pprof/profile", gin.WrapF(pprof.Profile))
+		debug.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/pprof/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/pprof/trace", gin.WrapF(pprof.Trace))
+		debug.GET("/pprof/:name", func(c *gin.Context) {
+			pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 管理接口可以读取进程内存和配置，未指定监听地址时只监听本机
+	host := cfg.AdminHost
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	return &http.Server{
+		Addr:    net.JoinHostPort(host, strconv.Itoa(cfg.AdminPort)),
+		Handler: router,
+	}
+}
+
+// runAdmin 在单独的端口上启动管理服务
+func (s *Server) runAdmin() {
+	if s.adminServer == nil {
+		return
+	}
+
+	go func() {
+		slog.Info("管理服务启动", "addr", s.adminServer.Addr)
+		if err := s.adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("管理服务异常退出", "error", err)
+		}
+	}()
+}
@@ -2,34 +2,122 @@ package app
 
 import (
 	"gin-server-template/internal/controller"
+	"gin-server-template/internal/metrics"
 	"gin-server-template/internal/middleware"
+	"gin-server-template/internal/service"
+	"gin-server-template/pkg/ratelimit"
+	"gin-server-template/pkg/storage"
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// avatarUploadPath 头像上传接口的路由，请求体大小上限单独设置
+	avatarUploadPath = "/api/v1/users/avatar"
+
+	// multipartOverhead 上传文件时为multipart表单的边界和字段头预留的字节数
+	multipartOverhead = 64 << 10
 )
 
 // setupRoutes 配置所有API路由
 func (s *Server) setupRoutes() {
 	// 创建控制器实例
 	userController := controller.NewUserController()
+	oauthController := controller.NewOAuthController()
+
+	// 健康检查路由，供编排系统探测
+	s.router.GET("/healthz", s.health.Liveness)
+	s.router.GET("/readyz", s.health.Readiness)
+
+	// 未配置独立监听地址时，指标端点与API共用端口
+	if s.config.Metrics.Enabled && s.config.Metrics.Addr == "" {
+		s.router.GET(s.metricsPath(), gin.WrapH(metrics.Handler()))
+	}
+
+	// 限流状态保存在进程内，多实例部署时可替换为共享存储
+	rateLimitStore := ratelimit.NewMemoryStore(s.config.RateLimit.MaxKeys)
+
+	// 认证之前按客户端IP限流，携带无效令牌的请求同样计入配额
+	ipLimit := s.rateLimit(rateLimitStore, "ip")
 
 	// 公共路由组
 	public := s.router.Group("/api/v1")
 	{
-		// 用户相关路由
-		userGroup := public.Group("/users")
+		// 用户相关路由，注册、登录和确认邮箱按客户端IP限流；登录响应包含令牌，禁止缓存
+		userGroup := public.Group("/users", s.rateLimit(rateLimitStore, "auth")...)
+		userGroup.Use(middleware.NoStore(), middleware.RequireJSON())
 		{
 			userGroup.POST("/register", userController.Register)
 			userGroup.POST("/login", userController.Login)
+			userGroup.POST("/email/confirm", userController.ConfirmEmail)
 		}
+
+		// OAuth2协议端点，由客户端自行认证；令牌端点按客户端IP限流
+		oauthGroup := public.Group("/oauth")
+		{
+			oauthGroup.POST("/token", append(s.rateLimit(rateLimitStore, "auth"), oauthController.Token)...)
+			oauthGroup.POST("/introspect", append(ipLimit, oauthController.Introspect)...)
+			oauthGroup.POST("/revoke", append(ipLimit, oauthController.Revoke)...)
+		}
+
+		// 授权端点由浏览器访问，通过登录表单认证用户，提交表单与登录接口使用同一限流策略
+		authorizeGroup := public.Group("/oauth/authorize", middleware.NoStore())
+		{
+			authorizeGroup.GET("", oauthController.Authorize)
+			authorizeGroup.POST("", append(s.rateLimit(rateLimitStore, "auth"), oauthController.AuthorizeLogin)...)
+		}
+
+		// 接受OAuth2访问令牌的资源接口，按令牌的权限范围授权
+		userinfo := append(append(ipLimit, middleware.NoStore(), middleware.OAuthAuth(service.ScopeProfile)), s.rateLimit(rateLimitStore, "api")...)
+		oauthGroup.GET("/userinfo", append(userinfo, oauthController.UserInfo)...)
 	}
 
-	// 需要认证的路由组
-	authorized := s.router.Group("/api/v1")
-	authorized.Use(middleware.JWTAuth())
+	// 需要认证的路由组，响应包含用户数据，禁止缓存；请求体只接受JSON
+	authorized := s.router.Group("/api/v1", ipLimit...)
+	authorized.Use(middleware.NoStore(), middleware.JWTAuth(), middleware.RequireJSON())
+	authorized.Use(s.rateLimit(rateLimitStore, "api")...)
 	{
 		// 用户相关路由
 		userGroup := authorized.Group("/users")
 		{
 			userGroup.GET("/profile", userController.GetProfile)
 			userGroup.PUT("/profile", userController.UpdateProfile)
+			userGroup.PATCH("/profile", userController.PatchProfile)
+			userGroup.DELETE("/avatar", userController.DeleteAvatar)
 		}
 	}
+
+	// 头像上传，请求体为multipart表单，不经过RequireJSON
+	upload := append(append(ipLimit, middleware.NoStore(), middleware.JWTAuth()), s.rateLimit(rateLimitStore, "api")...)
+	s.router.POST(avatarUploadPath, append(upload, userController.UploadAvatar)...)
+
+	// 本地存储的文件由本服务提供访问，对象键包含随机目录，内容不会改变
+	storageCfg := s.config.Storage
+	if storageCfg.Driver != storage.DriverS3 && storageCfg.Local.Dir != "" && strings.HasPrefix(storageCfg.BaseURL, "/") {
+		uploads := s.router.Group(storageCfg.BaseURL, func(c *gin.Context) {
+			c.Header("Cache-Control", "public, max-age=31536000, immutable")
+			c.Next()
+		})
+		uploads.Static("/", storageCfg.Local.Dir)
+	}
+}
+
+// rateLimit 返回指定策略的限流中间件，未启用限流或策略未配置时返回空列表
+func (s *Server) rateLimit(store ratelimit.Store, name string) []gin.HandlerFunc {
+	if !s.config.RateLimit.Enabled {
+		return nil
+	}
+
+	policy, ok := s.config.RateLimit.Policies[name]
+	if !ok {
+		return nil
+	}
+	if policy.Requests <= 0 || policy.Period <= 0 {
+		slog.Warn("限流策略配置无效，已忽略", "policy", name)
+		return nil
+	}
+
+	return []gin.HandlerFunc{middleware.RateLimit(store, name, policy)}
 }
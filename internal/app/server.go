@@ -1,19 +1,37 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"gin-server-template/internal/config"
+	"gin-server-template/internal/controller"
 	"gin-server-template/internal/database"
+	"gin-server-template/internal/metrics"
 	"gin-server-template/internal/middleware"
-	"log"
+	"gin-server-template/internal/service"
+	"gin-server-template/internal/validation"
+	"gin-server-template/pkg/i18n"
+	"gin-server-template/pkg/response"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pires/go-proxyproto"
 )
 
 // Server 表示HTTP服务器及其依赖项
 type Server struct {
-	config *config.Config
-	router *gin.Engine
+	config      *config.Config
+	router      *gin.Engine
+	httpServer  *http.Server
+	adminServer *http.Server
+	health      *controller.HealthController
+	cors        *middleware.CORS
+	stopCleanup context.CancelFunc
 }
 
 // NewServer 创建并配置一个新的Server实例
@@ -23,23 +41,83 @@ func NewServer(cfg *config.Config) *Server {
 
 	// 初始化数据库连接
 	if err := database.InitDatabase(&cfg.Database); err != nil {
-		log.Fatalf("数据库初始化失败: %v", err)
+		slog.Error("数据库初始化失败", "error", err)
+		os.Exit(1)
 	}
 
-	// 创建Gin引擎
-	router := gin.Default()
+	// 注册自定义校验规则
+	if err := validation.Register(); err != nil {
+		slog.Error("注册校验规则失败", "error", err)
+		os.Exit(1)
+	}
+
+	// 密码哈希算法或Argon2参数配置错误时拒绝启动，不静默改用其他算法
+	if _, err := service.NewPasswordHasher(&cfg.Password); err != nil {
+		slog.Error("密码哈希配置无效", "error", err)
+		os.Exit(1)
+	}
+
+	// 加载多语言消息目录，加载失败时使用代码中的默认中文信息
+	if err := i18n.LoadDir("configs/locales"); err != nil {
+		slog.Warn("加载消息目录失败", "error", err)
+	}
+
+	// 创建Gin引擎，访问日志由middleware.Logger以结构化格式输出，不使用gin自带的日志中间件
+	router := gin.New()
 
-	// 设置受信任的代理
-	router.SetTrustedProxies([]string{"127.0.0.1"})
+	// 设置受信任的代理，只有来自这些地址的请求才会按client_ip_headers解析客户端IP
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		slog.Error("受信任代理配置无效", "error", err)
+		os.Exit(1)
+	}
+	if len(cfg.Server.ClientIPHeaders) > 0 {
+		router.RemoteIPHeaders = cfg.Server.ClientIPHeaders
+	}
 
 	// 添加全局中间件
-	router.Use(middleware.Logger())
+	// RequestID需要最先执行，后续中间件的日志和错误响应都依赖请求ID；
+	// 压缩位于ErrorHandler之前，错误响应同样会被压缩；
+	// Recovery位于ErrorHandler之后，panic按统一格式返回，并由Metrics和Logger记录为500
+	router.Use(middleware.RequestID(), middleware.Tracing(), middleware.Metrics(), middleware.Logger(), middleware.Locale())
+	if cfg.Compression.Enabled {
+		router.Use(middleware.Compress(cfg.Compression))
+	}
+	router.Use(response.ErrorHandler(response.ErrorOptions{
+		Format:          cfg.Response.ErrorFormat,
+		ProblemTypeBase: cfg.Response.ProblemTypeBase,
+	}), middleware.Recovery())
+
+	// 跨域中间件注册在ErrorHandler之后，被拒绝的预检请求按统一格式返回错误；
+	// 全局中间件对未匹配路由同样生效，因此无需为预检请求注册OPTIONS路由
+	var cors *middleware.CORS
+	if cfg.CORS.Enabled {
+		var err error
+		if cors, err = middleware.NewCORS(cfg.CORS); err != nil {
+			slog.Error("跨域配置无效", "error", err)
+			os.Exit(1)
+		}
+		router.Use(cors.Handler())
+	}
+
+	// 安全响应头和请求体大小限制，超限的请求在绑定请求体之前即被拒绝；
+	// 头像上传的请求体为multipart表单，在文件大小上限之外预留表单编码的开销
+	router.Use(middleware.SecurityHeaders(cfg.Security))
+	router.Use(middleware.BodyLimit(cfg.Security.MaxBodySize, map[string]int64{
+		avatarUploadPath: cfg.Avatar.SizeLimit() + multipartOverhead,
+	}))
 
 	// 创建服务器实例
 	s := &Server{
 		config: cfg,
 		router: router,
+		health: controller.NewHealthController(),
+		cors:   cors,
+	}
+	s.httpServer = &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler: router,
 	}
+	s.adminServer = s.newAdminServer()
 
 	// 设置路由
 	s.setupRoutes()
@@ -49,7 +127,150 @@ func NewServer(cfg *config.Config) *Server {
 
 // Run 启动HTTP服务器
 func (s *Server) Run() error {
-	return s.router.Run(fmt.Sprintf(":%d", s.config.Server.Port))
+	s.runMetrics()
+	s.runAdmin()
+	s.runCleanup()
+	s.watchConfig()
+
+	listener, err := s.listen()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("HTTP服务启动", "addr", s.httpServer.Addr, "proxy_protocol", s.config.Server.ProxyProtocol)
+	if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// listen 监听API端口，开启proxy_protocol时解析PROXY协议头，以其中的源地址作为连接的远端地址
+//
+// 只接受来自受信任代理的PROXY协议头，其他连接携带的协议头会被忽略。
+func (s *Server) listen() (net.Listener, error) {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if !s.config.Server.ProxyProtocol {
+		return listener, nil
+	}
+
+	policy, err := proxyproto.LaxWhiteListPolicy(s.config.Server.TrustedProxies)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &proxyproto.Listener{Listener: listener, Policy: policy}, nil
+}
+
+// Shutdown 优雅关闭HTTP服务器
+//
+// 先将就绪检查标记为不可用，等待shutdown_delay让负载均衡摘除流量，
+// 再停止接收新请求并等待处理中的请求完成，最长等待shutdown_timeout。
+func (s *Server) Shutdown() error {
+	s.health.SetShuttingDown()
+	if s.stopCleanup != nil {
+		s.stopCleanup()
+	}
+
+	if delay := time.Duration(s.config.Server.ShutdownDelay) * time.Second; delay > 0 {
+		slog.Info("等待流量摘除", "delay", delay)
+		time.Sleep(delay)
+	}
+
+	timeout := time.Duration(s.config.Server.ShutdownTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 管理端口随主服务一同关闭
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			slog.Error("关闭管理服务失败", "error", err)
+		}
+	}
+
+	return s.httpServer.Shutdown(ctx)
+}
+
+// metricsPath 返回指标端点路径
+func (s *Server) metricsPath() string {
+	if s.config.Metrics.Path != "" {
+		return s.config.Metrics.Path
+	}
+	return "/metrics"
+}
+
+// runMetrics 配置了独立监听地址时，在单独的端口上暴露指标
+func (s *Server) runMetrics() {
+	if !s.config.Metrics.Enabled || s.config.Metrics.Addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(s.metricsPath(), metrics.Handler())
+
+	go func() {
+		slog.Info("指标服务启动", "addr", s.config.Metrics.Addr)
+		if err := http.ListenAndServe(s.config.Metrics.Addr, mux); err != nil {
+			slog.Error("指标服务异常退出", "error", err)
+		}
+	}()
+}
+
+// runCleanup 使用MySQL时定期删除过期的授权码和撤销记录，MongoDB由TTL索引自动清理
+func (s *Server) runCleanup() {
+	if s.config.Database.Driver != "mysql" {
+		return
+	}
+
+	interval := time.Duration(s.config.OAuth.CleanupInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopCleanup = cancel
+	oauthService := service.NewOAuthService()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := oauthService.DeleteExpired(ctx)
+				if err != nil {
+					slog.Error("清理过期的OAuth2记录失败", "error", err)
+					continue
+				}
+				if deleted > 0 {
+					slog.Info("已清理过期的OAuth2记录", "count", deleted)
+				}
+			}
+		}
+	}()
+}
+
+// watchConfig 监听配置文件，允许的跨域来源变化时无需重启即可生效
+func (s *Server) watchConfig() {
+	if s.cors == nil {
+		return
+	}
+
+	err := config.Watch("configs/config.yaml", func(cfg *config.Config) {
+		if err := s.cors.SetAllowedOrigins(cfg.CORS.AllowedOrigins); err != nil {
+			slog.Error("跨域来源配置无效，继续使用原配置", "error", err)
+		}
+	})
+	if err != nil {
+		slog.Warn("监听配置文件失败", "error", err)
+	}
 }
 
 // Close 关闭服务器并释放资源
@@ -58,15 +279,15 @@ func (s *Server) Close() {
 	switch s.config.Database.Driver {
 	case "mysql":
 		if err := database.CloseMySQL(); err != nil {
-			log.Printf("关闭MySQL连接失败: %v", err)
+			slog.Error("关闭MySQL连接失败", "error", err)
 		} else {
-			log.Println("MySQL连接已关闭")
+			slog.Info("MySQL连接已关闭")
 		}
 	case "mongodb":
 		if err := database.CloseMongoDB(); err != nil {
-			log.Printf("关闭MongoDB连接失败: %v", err)
+			slog.Error("关闭MongoDB连接失败", "error", err)
 		} else {
-			log.Println("MongoDB连接已关闭")
+			slog.Info("MongoDB连接已关闭")
 		}
 	}
 }
@@ -1,20 +1,46 @@
 package config
 
 import (
+	"log/slog"
+	"reflect"
+
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
 // Config 应用配置结构体
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	JWT         JWTConfig         `mapstructure:"jwt"`
+	OAuth       OAuthConfig       `mapstructure:"oauth"`
+	Password    PasswordConfig    `mapstructure:"password"`
+	Response    ResponseConfig    `mapstructure:"response"`
+	Log         LogConfig         `mapstructure:"log"`
+	Metrics     MetricsConfig     `mapstructure:"metrics"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
+	CORS        CORSConfig        `mapstructure:"cors"`
+	Security    SecurityConfig    `mapstructure:"security"`
+	Compression CompressionConfig `mapstructure:"compression"`
+	Mail        MailConfig        `mapstructure:"mail"`
+	EmailChange EmailChangeConfig `mapstructure:"email_change"`
+	Storage     StorageConfig     `mapstructure:"storage"`
+	Avatar      AvatarConfig      `mapstructure:"avatar"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port            int      `mapstructure:"port"`
+	Mode            string   `mapstructure:"mode"`
+	ShutdownDelay   int      `mapstructure:"shutdown_delay"`    // 就绪检查失败后等待多久再停止接收请求（秒）
+	ShutdownTimeout int      `mapstructure:"shutdown_timeout"`  // 等待处理中请求完成的最长时间（秒）
+	AdminHost       string   `mapstructure:"admin_host"`        // 管理端口的监听地址，为空时只监听127.0.0.1
+	AdminPort       int      `mapstructure:"admin_port"`        // 管理端口，为0时不启动
+	AdminToken      string   `mapstructure:"admin_token"`       // 访问管理端口所需的令牌
+	TrustedProxies  []string `mapstructure:"trusted_proxies"`   // 受信任代理的IP或CIDR，只信任来自这些地址的客户端IP请求头和PROXY协议头
+	ClientIPHeaders []string `mapstructure:"client_ip_headers"` // 读取客户端IP的请求头，按顺序尝试，如X-Forwarded-For、X-Real-IP、CF-Connecting-IP
+	ProxyProtocol   bool     `mapstructure:"proxy_protocol"`    // 监听端口是否接受PROXY协议头（v1/v2）
 }
 
 // DatabaseConfig 数据库配置
@@ -38,6 +64,227 @@ type JWTConfig struct {
 	Issuer string `mapstructure:"issuer"`
 }
 
+// OAuthConfig OAuth2授权服务器配置
+type OAuthConfig struct {
+	CodeExpire        int      `mapstructure:"code_expire"`         // 授权码有效期（秒）
+	AccessTokenExpire int      `mapstructure:"access_token_expire"` // 访问令牌有效期（秒）
+	Scopes            []string `mapstructure:"scopes"`              // 注册客户端时可以申请的权限范围
+	CleanupInterval   int      `mapstructure:"cleanup_interval"`    // 清理过期授权码和撤销记录的间隔（秒），仅MySQL使用
+}
+
+// PasswordConfig 密码哈希配置
+type PasswordConfig struct {
+	Algorithm  string       `mapstructure:"algorithm"` // 可选值: bcrypt, argon2id
+	BcryptCost int          `mapstructure:"bcrypt_cost"`
+	Argon2     Argon2Config `mapstructure:"argon2"`
+}
+
+// Argon2Config Argon2id参数配置
+type Argon2Config struct {
+	Memory      uint32 `mapstructure:"memory"` // KiB
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
+}
+
+// ResponseConfig 响应格式配置
+type ResponseConfig struct {
+	ErrorFormat     string `mapstructure:"error_format"`      // 可选值: envelope, problem
+	ProblemTypeBase string `mapstructure:"problem_type_base"` // Problem Details的type前缀
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level  string `mapstructure:"level"`  // 可选值: debug, info, warn, error
+	Format string `mapstructure:"format"` // 可选值: json, text
+	Output string `mapstructure:"output"` // stdout, stderr 或日志文件路径
+}
+
+// MetricsConfig 指标配置
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"` // 指标端点路径，默认/metrics
+	Addr    string `mapstructure:"addr"` // 独立监听地址，如:9090；为空时挂载在API端口上
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	ServiceName string  `mapstructure:"service_name"`
+	Endpoint    string  `mapstructure:"endpoint"`     // OTLP/HTTP接收端地址
+	Insecure    bool    `mapstructure:"insecure"`     // 使用HTTP而非HTTPS连接接收端
+	SampleRatio float64 `mapstructure:"sample_ratio"` // 采样比例，取值0~1
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Enabled  bool                       `mapstructure:"enabled"`
+	MaxKeys  int                        `mapstructure:"max_keys"` // 进程内存储最多保存的计数键数量，超出时淘汰最久未使用的键
+	Policies map[string]RateLimitPolicy `mapstructure:"policies"` // 按路由组名称配置的限流策略
+}
+
+// RateLimitPolicy 限流策略
+type RateLimitPolicy struct {
+	Requests int      `mapstructure:"requests"` // 每个周期允许的请求数
+	Period   int      `mapstructure:"period"`   // 周期（秒）
+	Key      string   `mapstructure:"key"`      // 限流维度，可选值: ip, user, api_key
+	APIKeys  []string `mapstructure:"api_keys"` // 维度为api_key时已登记的API Key，未登记的Key按客户端IP计数
+}
+
+// CORSConfig 跨域资源共享配置
+type CORSConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`   // 允许的来源，支持https://*.example.com形式的子域名通配和*
+	AllowedMethods   []string `mapstructure:"allowed_methods"`   // 允许的请求方法
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`   // 允许的请求头，*表示允许预检请求中声明的所有请求头
+	ExposedHeaders   []string `mapstructure:"exposed_headers"`   // 允许浏览器读取的响应头
+	AllowCredentials bool     `mapstructure:"allow_credentials"` // 是否允许携带Cookie等凭证
+	MaxAge           int      `mapstructure:"max_age"`           // 预检结果缓存时间（秒）
+}
+
+// SecurityConfig 安全响应头和请求限制配置
+type SecurityConfig struct {
+	HSTSMaxAge            int    `mapstructure:"hsts_max_age"`            // Strict-Transport-Security的max-age（秒），为0时不设置
+	HSTSIncludeSubdomains bool   `mapstructure:"hsts_include_subdomains"` // HSTS是否包含子域名
+	FrameOptions          string `mapstructure:"frame_options"`           // X-Frame-Options，如DENY、SAMEORIGIN
+	ReferrerPolicy        string `mapstructure:"referrer_policy"`         // Referrer-Policy
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"` // Content-Security-Policy，为空时不设置
+	MaxBodySize           int64  `mapstructure:"max_body_size"`           // 请求体大小上限（字节），为0时不限制
+}
+
+// CompressionConfig 响应压缩配置
+type CompressionConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	MinSize      int      `mapstructure:"min_size"`      // 响应体达到该大小（字节）才压缩
+	ContentTypes []string `mapstructure:"content_types"` // 可压缩的内容类型，不含参数
+}
+
+// MailConfig 邮件发送配置
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"` // 可选值: log, smtp；log只将邮件内容写入日志，用于开发环境
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"` // 发件人地址
+}
+
+// EmailChangeConfig 修改邮箱流程配置
+type EmailChangeConfig struct {
+	TokenExpire int    `mapstructure:"token_expire"` // 确认链接有效期（秒）
+	ConfirmURL  string `mapstructure:"confirm_url"`  // 确认页面地址，{token}会被替换为确认令牌
+}
+
+// StorageConfig 对象存储配置
+type StorageConfig struct {
+	Driver  string             `mapstructure:"driver"`   // 可选值: local, s3
+	BaseURL string             `mapstructure:"base_url"` // 对象访问地址前缀；本地存储配置为以/开头的路径时由本服务提供访问
+	Local   LocalStorageConfig `mapstructure:"local"`
+	S3      S3StorageConfig    `mapstructure:"s3"`
+}
+
+// LocalStorageConfig 本地文件系统存储配置
+type LocalStorageConfig struct {
+	Dir string `mapstructure:"dir"` // 保存目录
+}
+
+// S3StorageConfig S3兼容存储配置
+type S3StorageConfig struct {
+	Endpoint  string `mapstructure:"endpoint"` // 如s3.amazonaws.com、localhost:9000
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	UseSSL    bool   `mapstructure:"use_ssl"`
+	PathStyle bool   `mapstructure:"path_style"` // 以endpoint/bucket/key形式访问，MinIO等自建服务通常需要开启
+}
+
+// AvatarConfig 头像上传配置
+type AvatarConfig struct {
+	MaxSize int64 `mapstructure:"max_size"` // 上传文件大小上限（字节）
+	Sizes   []int `mapstructure:"sizes"`    // 生成的正方形头像边长（像素），第一个尺寸的地址保存为用户头像
+}
+
+// defaultAvatarMaxSize 未配置时头像文件的大小上限
+const defaultAvatarMaxSize = 5 << 20
+
+// SizeLimit 返回头像文件的大小上限，未配置时为5MB
+func (c AvatarConfig) SizeLimit() int64 {
+	if c.MaxSize <= 0 {
+		return defaultAvatarMaxSize
+	}
+	return c.MaxSize
+}
+
+// secretMask 脱敏后的占位符
+const secretMask = "******"
+
+// Sanitized 返回隐藏了密码、密钥等敏感信息的配置副本
+func (c Config) Sanitized() Config {
+	mask := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		return secretMask
+	}
+
+	c.Server.AdminToken = mask(c.Server.AdminToken)
+	c.Database.Password = mask(c.Database.Password)
+	c.JWT.Secret = mask(c.JWT.Secret)
+	c.Mail.Password = mask(c.Mail.Password)
+	c.Storage.S3.SecretKey = mask(c.Storage.S3.SecretKey)
+
+	// 限流策略保存在map中，复制后再修改，避免改动原配置
+	if c.RateLimit.Policies != nil {
+		policies := make(map[string]RateLimitPolicy, len(c.RateLimit.Policies))
+		for name, policy := range c.RateLimit.Policies {
+			if policy.APIKeys != nil {
+				keys := make([]string, len(policy.APIKeys))
+				for i, key := range policy.APIKeys {
+					keys[i] = mask(key)
+				}
+				policy.APIKeys = keys
+			}
+			policies[name] = policy
+		}
+		c.RateLimit.Policies = policies
+	}
+	return c
+}
+
+// Map 将配置转换为以配置文件键名为键的map，便于以与配置文件一致的结构输出
+func (c Config) Map() map[string]interface{} {
+	return structToMap(reflect.ValueOf(c))
+}
+
+// structToMap 按mapstructure标签递归转换结构体
+func structToMap(v reflect.Value) map[string]interface{} {
+	result := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			key = field.Name
+		}
+
+		value := v.Field(i)
+		switch {
+		case value.Kind() == reflect.Struct:
+			result[key] = structToMap(value)
+		case value.Kind() == reflect.Map && value.Type().Elem().Kind() == reflect.Struct:
+			m := make(map[string]interface{}, value.Len())
+			for _, k := range value.MapKeys() {
+				m[k.String()] = structToMap(value.MapIndex(k))
+			}
+			result[key] = m
+		default:
+			result[key] = value.Interface()
+		}
+	}
+	return result
+}
+
 // LoadConfig 加载配置文件
 func LoadConfig(configPath string) (*Config, error) {
 	v := viper.New()
@@ -53,4 +300,27 @@ func LoadConfig(configPath string) (*Config, error) {
 	}
 
 	return &config, nil
+}
+
+// Watch 监听配置文件变化，文件修改后重新加载配置并调用onChange，加载失败时保留原配置
+func Watch(configPath string, onChange func(*Config)) error {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+
+	v.OnConfigChange(func(event fsnotify.Event) {
+		var config Config
+		if err := v.Unmarshal(&config); err != nil {
+			slog.Error("重新加载配置失败", "file", event.Name, "error", err)
+			return
+		}
+		slog.Info("配置文件已重新加载", "file", event.Name)
+		onChange(&config)
+	})
+	v.WatchConfig()
+
+	return nil
 }
\ No newline at end of file
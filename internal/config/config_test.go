@@ -0,0 +1,21 @@
+package config
+
+import "testing"
+
+func TestSanitizedAPIKeys(t *testing.T) {
+	cfg := Config{RateLimit: RateLimitConfig{Policies: map[string]RateLimitPolicy{
+		"api": {Requests: 10, Period: 60, Key: "api_key", APIKeys: []string{"key-1", "key-2"}},
+	}}}
+
+	sanitized := cfg.Sanitized()
+	for _, key := range sanitized.RateLimit.Policies["api"].APIKeys {
+		if key != secretMask {
+			t.Errorf("APIKeys包含未脱敏的值: %q", key)
+		}
+	}
+
+	// 脱敏不能修改原配置
+	if got := cfg.RateLimit.Policies["api"].APIKeys[0]; got != "key-1" {
+		t.Errorf("原配置的APIKeys[0] = %q, want key-1", got)
+	}
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"gin-server-template/internal/config"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/service"
+	"gin-server-template/internal/validation"
+	"gin-server-template/pkg/response"
+	"gin-server-template/pkg/version"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminController 管理接口控制器
+type AdminController struct {
+	config       *config.Config
+	auditService *service.AuditService
+}
+
+// NewAdminController 创建管理接口控制器实例
+func NewAdminController(cfg *config.Config) *AdminController {
+	return &AdminController{
+		config:       cfg,
+		auditService: service.NewAuditService(),
+	}
+}
+
+// BuildInfo 返回构建信息
+func (c *AdminController) BuildInfo(ctx *gin.Context) {
+	response.Success(ctx, version.Get())
+}
+
+// Config 返回脱敏后的生效配置
+func (c *AdminController) Config(ctx *gin.Context) {
+	response.Success(ctx, c.config.Sanitized().Map())
+}
+
+// ListAuditEventsRequest 审计事件查询请求
+type ListAuditEventsRequest struct {
+	Action    string    `form:"action"`
+	ActorID   string    `form:"actor_id"`
+	TargetID  string    `form:"target_id"`
+	RequestID string    `form:"request_id"`
+	Success   *bool     `form:"success"`
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page      int       `form:"page" binding:"omitempty,min=1"`
+	PageSize  int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// ListAuditEvents 按条件分页查询审计事件
+func (c *AdminController) ListAuditEvents(ctx *gin.Context) {
+	var req ListAuditEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(validation.BindError(ctx, err))
+		return
+	}
+
+	filter := entity.AuditFilter{
+		Action:    req.Action,
+		ActorID:   req.ActorID,
+		TargetID:  req.TargetID,
+		RequestID: req.RequestID,
+		Success:   req.Success,
+		From:      req.From,
+		To:        req.To,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+	}
+	events, total, err := c.auditService.List(ctx.Request.Context(), &filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, gin.H{
+		"items":     events,
+		"total":     total,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+	})
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"errors"
+	"gin-server-template/internal/entity"
+	"gin-server-template/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newAuditEvent 创建带有客户端信息的审计事件
+func newAuditEvent(ctx *gin.Context, action string) *entity.AuditEvent {
+	return &entity.AuditEvent{
+		Action:    action,
+		Success:   true,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
+
+// auditFailure 将事件标记为失败，业务错误使用其默认信息作为原因，其他错误不记录细节
+func auditFailure(event *entity.AuditEvent, err error) {
+	event.Success = false
+
+	var appErr *response.Error
+	if errors.As(err, &appErr) {
+		event.Reason = appErr.Message
+		return
+	}
+	event.Reason = "internal error"
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"gin-server-template/internal/config"
+	"gin-server-template/internal/database"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 依赖检查超时时间
+const healthCheckTimeout = 2 * time.Second
+
+// HealthController 健康检查控制器
+type HealthController struct {
+	driver       string
+	shuttingDown atomic.Bool
+}
+
+// NewHealthController 创建健康检查控制器实例
+func NewHealthController() *HealthController {
+	c := &HealthController{}
+	if cfg, err := config.LoadConfig("configs/config.yaml"); err == nil {
+		c.driver = cfg.Database.Driver
+	}
+	return c
+}
+
+// DependencyStatus 单个依赖的检查结果，就绪检查不需要认证，失败原因只记录在日志中
+type DependencyStatus struct {
+	Status    string  `json:"status"` // up 或 down
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// SetShuttingDown 标记服务正在关闭，此后就绪检查始终返回不可用
+func (c *HealthController) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Liveness 存活检查，只要进程能处理请求就返回成功
+func (c *HealthController) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness 就绪检查，检查数据库等依赖是否可用
+func (c *HealthController) Readiness(ctx *gin.Context) {
+	// 开始关闭后立即摘除流量，不再检查依赖
+	if c.shuttingDown.Load() {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
+		return
+	}
+
+	checks := map[string]DependencyStatus{}
+	status, code := "ok", http.StatusOK
+
+	if c.driver != "" && c.driver != "mock" {
+		result := checkDependency(ctx.Request.Context(), c.driver, func(ctx context.Context) error {
+			return database.Ping(ctx, c.driver)
+		})
+		checks[c.driver] = result
+		if result.Status != "up" {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+
+	ctx.JSON(code, gin.H{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+// checkDependency 在超时时间内执行依赖检查并记录耗时，检查失败时记录日志
+func checkDependency(ctx context.Context, name string, ping func(context.Context) error) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := ping(ctx)
+	result := DependencyStatus{
+		Status:    "up",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		// 错误信息可能包含数据库地址等内部信息，不在响应中返回
+		slog.Error("依赖检查失败", "dependency", name, "error", err)
+		result.Status = "down"
+	}
+	return result
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckDependencyHidesError(t *testing.T) {
+	result := checkDependency(context.Background(), "mysql", func(ctx context.Context) error {
+		return errors.New("dial tcp 10.0.0.5:3306: connect: connection refused")
+	})
+	if result.Status != "down" {
+		t.Errorf("Status = %q, want down", result.Status)
+	}
+
+	// 就绪检查无需认证，响应中不能包含数据库地址等错误详情
+	body, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "10.0.0.5") {
+		t.Errorf("响应包含错误详情: %s", body)
+	}
+}
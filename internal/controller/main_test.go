@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"fmt"
+	"gin-server-template/internal/validation"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testConfig 测试使用的配置，数据库使用共享的模拟实现
+const testConfig = `
+database:
+  driver: mock
+jwt:
+  secret: test_secret
+  expire: 1
+  issuer: test
+password:
+  algorithm: bcrypt
+  bcrypt_cost: 4
+mail:
+  driver: log
+email_change:
+  token_expire: 3600
+`
+
+// TestMain 控制器从工作目录下的configs/config.yaml读取配置，测试在临时目录中运行
+func TestMain(m *testing.M) {
+	if err := validation.Register(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	dir, err := os.MkdirTemp("", "controller-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := func() int {
+		defer os.RemoveAll(dir)
+		if err := os.MkdirAll(filepath.Join(dir, "configs"), 0o755); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := os.WriteFile(filepath.Join(dir, "configs", "config.yaml"), []byte(testConfig), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := os.Chdir(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return m.Run()
+	}()
+	os.Exit(code)
+}
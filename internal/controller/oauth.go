@@ -0,0 +1,469 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/service"
+	"gin-server-template/internal/validation"
+	"gin-server-template/pkg/i18n"
+	"gin-server-template/pkg/response"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// OAuthController OAuth2授权服务器控制器
+type OAuthController struct {
+	oauthService *service.OAuthService
+	userService  *service.UserService
+	auditService *service.AuditService
+}
+
+// NewOAuthController 创建OAuth2控制器实例
+func NewOAuthController() *OAuthController {
+	return &OAuthController{
+		oauthService: service.NewOAuthService(),
+		userService:  service.NewUserService(),
+		auditService: service.NewAuditService(),
+	}
+}
+
+// RegisterClientRequest 客户端注册请求
+type RegisterClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" binding:"dive,url"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1"`
+	Scopes       []string `json:"scopes"`
+	Confidential bool     `json:"confidential"`
+	OwnerID      uint     `json:"owner_id"` // 客户端所属用户，可选
+}
+
+// RegisterClient 注册OAuth2客户端，只在管理端口上提供，由管理员为第三方应用登记
+func (c *OAuthController) RegisterClient(ctx *gin.Context) {
+	var req RegisterClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(validation.BindError(ctx, err))
+		return
+	}
+
+	// 指定所属用户时检查用户是否存在
+	if req.OwnerID != 0 {
+		if _, err := c.userService.GetUserByID(ctx.Request.Context(), req.OwnerID); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+
+	client := &entity.OAuthClient{
+		Name:         req.Name,
+		RedirectURIs: strings.Join(req.RedirectURIs, " "),
+		GrantTypes:   strings.Join(req.GrantTypes, " "),
+		Scopes:       strings.Join(req.Scopes, " "),
+		Confidential: req.Confidential,
+		OwnerID:      req.OwnerID,
+	}
+
+	secret, err := c.oauthService.RegisterClient(ctx.Request.Context(), client)
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if errors.As(err, &oauthErr) {
+			ctx.Error(response.ErrInvalidParams.WithMessage(oauthDescription(ctx, oauthErr)))
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	data := gin.H{
+		"client_id":     client.ClientID,
+		"name":          client.Name,
+		"redirect_uris": req.RedirectURIs,
+		"grant_types":   req.GrantTypes,
+		"scopes":        req.Scopes,
+		"confidential":  client.Confidential,
+	}
+	if secret != "" {
+		data["client_secret"] = secret
+	}
+	response.Success(ctx, data)
+}
+
+// AuthorizeRequest 授权请求参数，GET请求来自查询字符串，POST请求来自登录表单的隐藏字段
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
+
+// Authorize 授权端点，校验授权请求后显示登录表单
+//
+// 授权端点由浏览器通过重定向访问，无法携带Bearer令牌，用户在登录表单中输入凭证后由AuthorizeLogin签发授权码。
+func (c *OAuthController) Authorize(ctx *gin.Context) {
+	req, client, ok := c.authorizeRequest(ctx)
+	if !ok {
+		return
+	}
+
+	renderLoginPage(ctx, http.StatusOK, client, req, "")
+}
+
+// AuthorizeLogin 处理授权端点的登录表单，验证用户凭证后签发授权码并重定向回客户端
+func (c *OAuthController) AuthorizeLogin(ctx *gin.Context) {
+	req, client, ok := c.authorizeRequest(ctx)
+	if !ok {
+		return
+	}
+
+	username := ctx.PostForm("username")
+	event := newAuditEvent(ctx, entity.AuditActionLogin)
+	event.TargetType = entity.AuditSubjectUser
+	event.TargetID = username
+
+	user, err := c.userService.VerifyCredentials(ctx.Request.Context(), username, ctx.PostForm("password"))
+	if err != nil {
+		auditFailure(event, err)
+		c.auditService.Record(ctx.Request.Context(), event)
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			renderLoginPage(ctx, http.StatusUnauthorized, client, req, response.LocalizedMessage(ctx, service.ErrInvalidCredentials))
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	event.ActorType = entity.AuditSubjectUser
+	event.ActorID = strconv.FormatUint(uint64(user.ID), 10)
+	c.auditService.Record(ctx.Request.Context(), event)
+
+	code, err := c.oauthService.Authorize(
+		ctx.Request.Context(),
+		user.ID,
+		client,
+		req.RedirectURI,
+		req.Scope,
+		req.CodeChallenge,
+		req.CodeChallengeMethod,
+	)
+	if err != nil {
+		params := url.Values{"error": {"server_error"}, "state": {req.State}}
+		var oauthErr *service.OAuthError
+		if errors.As(err, &oauthErr) {
+			params.Set("error", oauthErr.Code)
+			params.Set("error_description", oauthDescription(ctx, oauthErr))
+		} else {
+			// 记录内部错误，响应仍按协议重定向
+			ctx.Error(err)
+		}
+		redirectWithParams(ctx, req.RedirectURI, params)
+		return
+	}
+
+	redirectWithParams(ctx, req.RedirectURI, url.Values{"code": {code}, "state": {req.State}})
+}
+
+// authorizeRequest 解析并校验授权请求，校验失败时已写入响应并返回false
+func (c *OAuthController) authorizeRequest(ctx *gin.Context) (*AuthorizeRequest, *entity.OAuthClient, bool) {
+	var req AuthorizeRequest
+	if err := ctx.ShouldBindWith(&req, binding.Form); err != nil {
+		ctx.Error(validation.BindError(ctx, err))
+		return nil, nil, false
+	}
+
+	// 回调地址校验通过之前，错误不能重定向到客户端
+	client, err := c.oauthService.GetClient(ctx.Request.Context(), req.ClientID, req.RedirectURI)
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if errors.As(err, &oauthErr) {
+			ctx.Error(response.ErrInvalidParams.WithMessage(oauthDescription(ctx, oauthErr)))
+			return nil, nil, false
+		}
+		ctx.Error(err)
+		return nil, nil, false
+	}
+
+	if req.ResponseType != "code" {
+		redirectWithParams(ctx, req.RedirectURI, url.Values{
+			"error":             {"unsupported_response_type"},
+			"error_description": {oauthMessage(ctx, "unsupported_response_type", "仅支持response_type=code")},
+			"state":             {req.State},
+		})
+		return nil, nil, false
+	}
+
+	return &req, client, true
+}
+
+// UserInfo 返回访问令牌所属用户的资料，令牌包含email权限范围时同时返回邮箱
+func (c *OAuthController) UserInfo(ctx *gin.Context) {
+	// 客户端凭证模式签发的令牌没有用户
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.Error(response.ErrForbidden)
+		return
+	}
+
+	user, err := c.userService.GetUserByID(ctx.Request.Context(), userID.(uint))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	data := gin.H{
+		"sub":      user.Username,
+		"username": user.Username,
+		"nickname": user.Nickname,
+		"avatar":   user.Avatar,
+	}
+	if slices.Contains(strings.Fields(ctx.GetString("scope")), service.ScopeEmail) {
+		data["email"] = user.Email
+	}
+	response.Success(ctx, data)
+}
+
+// Token 令牌端点，支持授权码模式和客户端凭证模式
+func (c *OAuthController) Token(ctx *gin.Context) {
+	client, ok := c.authenticateClient(ctx)
+	if !ok {
+		return
+	}
+
+	var (
+		result *service.TokenResult
+		err    error
+	)
+	switch ctx.PostForm("grant_type") {
+	case service.GrantTypeAuthorizationCode:
+		result, err = c.oauthService.ExchangeAuthorizationCode(
+			ctx.Request.Context(),
+			client,
+			ctx.PostForm("code"),
+			ctx.PostForm("redirect_uri"),
+			ctx.PostForm("code_verifier"),
+		)
+	case service.GrantTypeClientCredentials:
+		result, err = c.oauthService.ClientCredentials(ctx.Request.Context(), client, ctx.PostForm("scope"))
+	default:
+		oauthError(ctx, http.StatusBadRequest, "unsupported_grant_type",
+			oauthMessage(ctx, "unsupported_grant_type", "不支持的授权类型: %s", ctx.PostForm("grant_type")))
+		return
+	}
+
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if errors.As(err, &oauthErr) {
+			oauthError(ctx, http.StatusBadRequest, oauthErr.Code, oauthDescription(ctx, oauthErr))
+			return
+		}
+		ctx.Error(err)
+		oauthError(ctx, http.StatusInternalServerError, "server_error", oauthMessage(ctx, "token_failed", "签发令牌失败"))
+		return
+	}
+
+	// 令牌响应禁止缓存（RFC 6749第5.1节）
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+	ctx.JSON(http.StatusOK, gin.H{
+		"access_token": result.AccessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int64(result.ExpiresIn.Seconds()),
+		"scope":        result.Scope,
+	})
+}
+
+// Introspect 令牌内省端点（RFC 7662），只对机密客户端开放
+func (c *OAuthController) Introspect(ctx *gin.Context) {
+	client, ok := c.authenticateClient(ctx)
+	if !ok {
+		return
+	}
+
+	token := ctx.PostForm("token")
+	if token == "" {
+		oauthError(ctx, http.StatusBadRequest, "invalid_request", oauthMessage(ctx, "token_required", "缺少token参数"))
+		return
+	}
+
+	result, err := c.oauthService.Introspect(ctx.Request.Context(), client, token)
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if errors.As(err, &oauthErr) {
+			oauthError(ctx, http.StatusForbidden, oauthErr.Code, oauthDescription(ctx, oauthErr))
+			return
+		}
+		ctx.Error(err)
+		oauthError(ctx, http.StatusInternalServerError, "server_error", oauthMessage(ctx, "introspection_failed", "令牌内省失败"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Revoke 令牌撤销端点（RFC 7009）
+func (c *OAuthController) Revoke(ctx *gin.Context) {
+	client, ok := c.authenticateClient(ctx)
+	if !ok {
+		return
+	}
+
+	token := ctx.PostForm("token")
+	if token == "" {
+		oauthError(ctx, http.StatusBadRequest, "invalid_request", oauthMessage(ctx, "token_required", "缺少token参数"))
+		return
+	}
+
+	event := newAuditEvent(ctx, entity.AuditActionTokenRevoke)
+	event.ActorType = entity.AuditSubjectClient
+	event.ActorID = client.ClientID
+	event.TargetType = entity.AuditSubjectToken
+
+	jti, err := c.oauthService.Revoke(ctx.Request.Context(), client, token)
+	event.TargetID = jti
+	if err != nil {
+		auditFailure(event, err)
+		c.auditService.Record(ctx.Request.Context(), event)
+		ctx.Error(err)
+		oauthError(ctx, http.StatusServiceUnavailable, "temporarily_unavailable", oauthMessage(ctx, "revocation_failed", "撤销令牌失败"))
+		return
+	}
+
+	// 无效令牌不会被撤销，只记录实际撤销的令牌
+	if jti != "" {
+		c.auditService.Record(ctx.Request.Context(), event)
+	}
+
+	// 无论令牌是否有效都返回200
+	ctx.Status(http.StatusOK)
+}
+
+// authenticateClient 通过HTTP Basic认证或表单参数验证客户端
+func (c *OAuthController) authenticateClient(ctx *gin.Context) (*entity.OAuthClient, bool) {
+	clientID, clientSecret, ok := ctx.Request.BasicAuth()
+	if !ok {
+		clientID = ctx.PostForm("client_id")
+		clientSecret = ctx.PostForm("client_secret")
+	}
+
+	if clientID == "" {
+		oauthError(ctx, http.StatusUnauthorized, "invalid_client", oauthMessage(ctx, "client_auth_missing", "缺少客户端认证信息"))
+		return nil, false
+	}
+
+	client, err := c.oauthService.AuthenticateClient(ctx.Request.Context(), clientID, clientSecret)
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if errors.As(err, &oauthErr) {
+			oauthError(ctx, http.StatusUnauthorized, oauthErr.Code, oauthDescription(ctx, oauthErr))
+			return nil, false
+		}
+		ctx.Error(err)
+		oauthError(ctx, http.StatusInternalServerError, "server_error", oauthMessage(ctx, "client_auth_failed", "客户端认证失败"))
+		return nil, false
+	}
+
+	return client, true
+}
+
+// oauthError 按RFC 6749第5.2节返回错误响应
+func oauthError(ctx *gin.Context, status int, code, description string) {
+	if status == http.StatusUnauthorized {
+		ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	ctx.JSON(status, gin.H{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// oauthDescription 按当前请求的语言返回OAuth2错误的描述
+func oauthDescription(ctx *gin.Context, err *service.OAuthError) string {
+	return oauthMessage(ctx, err.Key, err.Description, err.Args...)
+}
+
+// oauthMessage 按当前请求的语言翻译消息目录中oauth.error下的错误描述，缺失时使用fallback
+func oauthMessage(ctx *gin.Context, key, fallback string, args ...interface{}) string {
+	return i18n.T(i18n.Locale(ctx), "oauth.error."+key, fallback, args...)
+}
+
+// redirectWithParams 将参数附加到回调地址并重定向
+func redirectWithParams(ctx *gin.Context, redirectURI string, params url.Values) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		ctx.Error(response.ErrInvalidParams.WithMessage(oauthMessage(ctx, "invalid_redirect_uri", "无效的回调地址")))
+		return
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	target.RawQuery = query.Encode()
+
+	ctx.Redirect(http.StatusFound, target.String())
+}
+
+// loginPage 授权端点的登录页面，隐藏字段原样提交授权请求参数
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Prompt}}</p>
+{{if .Error}}<p role="alert">{{.Error}}</p>
+{{end}}<form method="post" action="{{.Action}}">
+{{range $name, $value := .Fields}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}<p><label>{{.Username}} <input name="username" autocomplete="username" required></label></p>
+<p><label>{{.Password}} <input type="password" name="password" autocomplete="current-password" required></label></p>
+<p><button type="submit">{{.Submit}}</button></p>
+</form>
+</body>
+</html>
+`))
+
+// renderLoginPage 渲染授权端点的登录页面，message为登录失败的提示
+func renderLoginPage(ctx *gin.Context, status int, client *entity.OAuthClient, req *AuthorizeRequest, message string) {
+	locale := i18n.Locale(ctx)
+
+	var buf bytes.Buffer
+	err := loginPage.Execute(&buf, gin.H{
+		"Lang":     locale,
+		"Title":    i18n.T(locale, "oauth.login_title", "登录"),
+		"Prompt":   i18n.T(locale, "oauth.login_prompt", "%s 请求使用你的账号登录", client.Name),
+		"Error":    message,
+		"Action":   ctx.Request.URL.Path,
+		"Username": i18n.T(locale, "oauth.username", "用户名"),
+		"Password": i18n.T(locale, "oauth.password", "密码"),
+		"Submit":   i18n.T(locale, "oauth.submit", "登录并授权"),
+		"Fields": map[string]string{
+			"response_type":         req.ResponseType,
+			"client_id":             req.ClientID,
+			"redirect_uri":          req.RedirectURI,
+			"scope":                 req.Scope,
+			"state":                 req.State,
+			"code_challenge":        req.CodeChallenge,
+			"code_challenge_method": req.CodeChallengeMethod,
+		},
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/repository"
+	"gin-server-template/pkg/response"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegisterClientOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(response.ErrorHandler(response.ErrorOptions{}))
+	router.POST("/admin/oauth/clients", NewOAuthController().RegisterClient)
+
+	owner := &entity.User{Username: "client_owner", Email: "client_owner@example.com", Password: "x"}
+	if err := repository.NewUserRepository().Create(context.Background(), owner); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ownerID uint
+		status  int
+	}{
+		{"不指定所属用户", 0, http.StatusOK},
+		{"所属用户存在", owner.ID, http.StatusOK},
+		{"所属用户不存在", owner.ID + 1000, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"name": "app", "grant_types": ["client_credentials"], "confidential": true, "owner_id": ` + strconv.FormatUint(uint64(tt.ownerID), 10) + `}`
+			w := serveJSON(router, http.MethodPost, "/admin/oauth/clients", "application/json", body, nil)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status == http.StatusOK && !strings.Contains(w.Body.String(), `"client_secret"`) {
+				t.Errorf("body = %s, want client_secret", w.Body.String())
+			}
+		})
+	}
+}
@@ -1,35 +1,44 @@
 package controller
 
 import (
-	"gin-server-template/internal/config"
+	"context"
 	"gin-server-template/internal/entity"
 	"gin-server-template/internal/service"
+	"gin-server-template/internal/validation"
+	"gin-server-template/pkg/httpcache"
+	"gin-server-template/pkg/i18n"
+	"gin-server-template/pkg/mergepatch"
 	"gin-server-template/pkg/response"
 	"net/http"
-	"time"
+	"sort"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // UserController 用户控制器
 type UserController struct {
-	userService *service.UserService
+	userService  *service.UserService
+	tokenService *service.TokenService
+	auditService *service.AuditService
 }
 
 // NewUserController 创建用户控制器实例
 func NewUserController() *UserController {
 	return &UserController{
-		userService: service.NewUserService(),
+		userService:  service.NewUserService(),
+		tokenService: service.NewTokenService(),
+		auditService: service.NewAuditService(),
 	}
 }
 
 // RegisterRequest 用户注册请求
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=50"`
-	Password string `json:"password" binding:"required,min=6"`
-	Email    string `json:"email" binding:"required,email"`
-	Nickname string `json:"nickname"`
+	Username string `json:"username" binding:"required,min=3,max=50,username"`
+	Password string `json:"password" binding:"required,max_bytes=72,strong_password"`
+	Email    string `json:"email" binding:"required,email,max=100"`
+	Nickname string `json:"nickname" binding:"max=50"`
 }
 
 // LoginRequest 用户登录请求
@@ -38,18 +47,30 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// UpdateProfileRequest 更新个人资料请求
+// ProfilePatch PATCH个人资料时允许修改的字段及其校验规则，邮箱为注册时的必填项，不能清空；
+// 修改邮箱需要经过确认，见UpdateProfile。头像只能通过上传接口设置，见UploadAvatar
+type ProfilePatch struct {
+	Nickname string `json:"nickname" binding:"max=50"`
+	Email    string `json:"email" binding:"required,email,max=100"`
+}
+
+// UpdateProfileRequest 更新个人资料请求，头像只能通过上传接口设置
 type UpdateProfileRequest struct {
-	Nickname string `json:"nickname"`
-	Email    string `json:"email" binding:"omitempty,email"`
-	Avatar   string `json:"avatar"`
+	Nickname string `json:"nickname" binding:"max=50"`
+	Email    string `json:"email" binding:"omitempty,email,max=100"`
+	Version  uint   `json:"version"` // 读取资料时得到的版本号，提供时与当前版本不一致返回409
+}
+
+// ConfirmEmailRequest 确认修改邮箱请求
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required,max=128"`
 }
 
 // Register 用户注册
 func (c *UserController) Register(ctx *gin.Context) {
 	var req RegisterRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(ctx, "无效的请求参数")
+		ctx.Error(validation.BindError(ctx, err))
 		return
 	}
 
@@ -62,12 +83,22 @@ func (c *UserController) Register(ctx *gin.Context) {
 		Status:   1,
 	}
 
+	event := newAuditEvent(ctx, entity.AuditActionRegister)
+	event.TargetType = entity.AuditSubjectUser
+	event.TargetID = req.Username
+
 	// 调用服务层注册用户
-	if err := c.userService.Register(user); err != nil {
-		response.Fail(ctx, http.StatusInternalServerError, "注册失败: "+err.Error())
+	if err := c.userService.Register(ctx.Request.Context(), user); err != nil {
+		auditFailure(event, err)
+		c.auditService.Record(ctx.Request.Context(), event)
+		ctx.Error(err)
 		return
 	}
 
+	event.ActorType = entity.AuditSubjectUser
+	event.ActorID = strconv.FormatUint(uint64(user.ID), 10)
+	c.auditService.Record(ctx.Request.Context(), event)
+
 	response.Success(ctx, gin.H{"user_id": user.ID})
 }
 
@@ -75,21 +106,31 @@ func (c *UserController) Register(ctx *gin.Context) {
 func (c *UserController) Login(ctx *gin.Context) {
 	var req LoginRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(ctx, "无效的请求参数")
+		ctx.Error(validation.BindError(ctx, err))
 		return
 	}
 
+	event := newAuditEvent(ctx, entity.AuditActionLogin)
+	event.TargetType = entity.AuditSubjectUser
+	event.TargetID = req.Username
+
 	// 验证用户凭证
-	user, err := c.userService.VerifyCredentials(req.Username, req.Password)
+	user, err := c.userService.VerifyCredentials(ctx.Request.Context(), req.Username, req.Password)
 	if err != nil {
-		response.Unauthorized(ctx, "用户名或密码错误")
+		auditFailure(event, err)
+		c.auditService.Record(ctx.Request.Context(), event)
+		ctx.Error(err)
 		return
 	}
 
+	event.ActorType = entity.AuditSubjectUser
+	event.ActorID = strconv.FormatUint(uint64(user.ID), 10)
+	c.auditService.Record(ctx.Request.Context(), event)
+
 	// 生成JWT令牌
-	token, err := c.generateToken(user)
+	token, err := c.tokenService.GenerateUserToken(user)
 	if err != nil {
-		response.ServerError(ctx, "生成令牌失败")
+		ctx.Error(err)
 		return
 	}
 
@@ -109,87 +150,317 @@ func (c *UserController) GetProfile(ctx *gin.Context) {
 	// 从上下文中获取用户ID
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		response.Unauthorized(ctx, "未认证的请求")
+		ctx.Error(response.ErrUnauthorized)
 		return
 	}
 
 	// 获取用户信息
-	user, err := c.userService.GetUserByID(userID.(uint))
+	user, err := c.userService.GetUserByID(ctx.Request.Context(), userID.(uint))
 	if err != nil {
-		response.NotFound(ctx, "用户不存在")
+		ctx.Error(err)
+		return
+	}
+
+	// 允许客户端缓存，但每次使用前都需要通过条件请求验证
+	etag, err := setCacheValidators(ctx, user)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Header("Accept-Patch", mergepatch.ContentType)
+	if httpcache.NotModified(ctx.Request, etag, user.UpdatedAt) {
+		ctx.Status(http.StatusNotModified)
 		return
 	}
 
 	response.Success(ctx, user)
 }
 
-// UpdateProfile 更新用户个人资料
+// UpdateProfile 更新用户个人资料，为空的字段保持不变
+//
+// 邮箱不会直接修改：新邮箱记录为pending_email并发送确认链接，确认后才会生效，其他字段照常更新。
 func (c *UserController) UpdateProfile(ctx *gin.Context) {
+	var req UpdateProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(validation.BindError(ctx, err))
+		return
+	}
+
+	user := c.profileForUpdate(ctx)
+	if user == nil {
+		return
+	}
+	if req.Version != 0 && req.Version != user.Version {
+		ctx.Error(service.ErrUserConflict)
+		return
+	}
+
+	// 更新用户信息，同时记录实际变更的字段
+	changes := map[string]entity.AuditChange{}
+	if req.Nickname != "" {
+		applyChange(changes, "nickname", &user.Nickname, req.Nickname)
+	}
+	if req.Email != "" && req.Email != user.Email && !c.requestEmailChange(ctx, user, req.Email) {
+		return
+	}
+
+	c.saveProfile(ctx, user, changes)
+}
+
+// PatchProfile 按JSON Merge Patch（RFC 7396）部分更新个人资料，值为null的字段会被清空，
+// 邮箱修改与UpdateProfile一样需要确认
+//
+// 请求的内容类型必须为application/merge-patch+json，普通JSON的语义不同，不按补丁处理。
+func (c *UserController) PatchProfile(ctx *gin.Context) {
+	if !mergepatch.IsContentType(ctx.GetHeader("Content-Type")) {
+		ctx.Header("Accept-Patch", mergepatch.ContentType)
+		ctx.Error(response.ErrUnsupported)
+		return
+	}
+
+	patch, err := ctx.GetRawData()
+	if err != nil {
+		ctx.Error(validation.BindError(ctx, err))
+		return
+	}
+
+	user := c.profileForUpdate(ctx)
+	if user == nil {
+		return
+	}
+
+	// 以当前资料为基础合并补丁，ProfilePatch之外的字段不允许修改
+	fields := ProfilePatch{Nickname: user.Nickname, Email: user.Email}
+	unknown, err := mergepatch.Apply(&fields, patch)
+	if err != nil {
+		ctx.Error(validation.BindError(ctx, err))
+		return
+	}
+	if len(unknown) > 0 {
+		ctx.Error(validation.NotAllowedError(ctx, unknown))
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&fields); err != nil {
+		ctx.Error(validation.BindError(ctx, err))
+		return
+	}
+
+	changes := map[string]entity.AuditChange{}
+	applyChange(changes, "nickname", &user.Nickname, fields.Nickname)
+	if fields.Email != user.Email && !c.requestEmailChange(ctx, user, fields.Email) {
+		return
+	}
+
+	c.saveProfile(ctx, user, changes)
+}
+
+// profileForUpdate 获取当前用户的资料并校验条件请求头，失败时已上报错误并返回nil
+//
+// 客户端通过If-Match携带读取时的ETag，资料已被修改时拒绝覆盖。
+func (c *UserController) profileForUpdate(ctx *gin.Context) *entity.User {
 	// 从上下文中获取用户ID
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		response.Unauthorized(ctx, "未认证的请求")
-		return
+		ctx.Error(response.ErrUnauthorized)
+		return nil
 	}
 
-	var req UpdateProfileRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(ctx, "无效的请求参数")
-		return
+	user, err := c.userService.GetUserByID(ctx.Request.Context(), userID.(uint))
+	if err != nil {
+		ctx.Error(err)
+		return nil
 	}
 
-	// 获取用户信息
-	user, err := c.userService.GetUserByID(userID.(uint))
+	etag, err := setCacheValidators(ctx, user)
 	if err != nil {
-		response.NotFound(ctx, "用户不存在")
+		ctx.Error(err)
+		return nil
+	}
+	if httpcache.PreconditionFailed(ctx.Request, etag, user.UpdatedAt) {
+		ctx.Error(response.ErrPrecondition)
+		return nil
+	}
+	return user
+}
+
+// UploadAvatar 上传头像，请求为multipart/form-data，文件字段名为avatar
+//
+// 图片按内容识别类型并重新编码为标准尺寸，保存到对象存储后将地址写入头像字段，返回更新后的资料。
+func (c *UserController) UploadAvatar(ctx *gin.Context) {
+	if ctx.ContentType() != binding.MIMEMultipartPOSTForm {
+		ctx.Error(response.ErrUnsupported)
 		return
 	}
 
-	// 更新用户信息
-	if req.Nickname != "" {
-		user.Nickname = req.Nickname
+	header, err := ctx.FormFile("avatar")
+	if err != nil {
+		ctx.Error(validation.BindError(ctx, err))
+		return
 	}
-	if req.Email != "" {
-		user.Email = req.Email
+	file, err := header.Open()
+	if err != nil {
+		ctx.Error(err)
+		return
 	}
-	if req.Avatar != "" {
-		user.Avatar = req.Avatar
+	defer file.Close()
+
+	user := c.profileForUpdate(ctx)
+	if user == nil {
+		return
 	}
 
-	// 保存更新
-	if err := c.userService.UpdateUser(user); err != nil {
-		response.ServerError(ctx, "更新失败: "+err.Error())
+	c.changeAvatar(ctx, user, func(rctx context.Context) error {
+		return c.userService.UpdateAvatar(rctx, user, file)
+	})
+}
+
+// DeleteAvatar 清除头像并删除之前上传的头像文件，返回更新后的资料
+func (c *UserController) DeleteAvatar(ctx *gin.Context) {
+	user := c.profileForUpdate(ctx)
+	if user == nil {
 		return
 	}
 
+	c.changeAvatar(ctx, user, func(rctx context.Context) error {
+		return c.userService.DeleteAvatar(rctx, user)
+	})
+}
+
+// changeAvatar 执行头像修改并记录审计事件，成功时返回更新后的资料
+func (c *UserController) changeAvatar(ctx *gin.Context, user *entity.User, change func(context.Context) error) {
+	event := newAuditEvent(ctx, entity.AuditActionProfileUpdate)
+	event.ActorType = entity.AuditSubjectUser
+	event.ActorID = strconv.FormatUint(uint64(user.ID), 10)
+	event.TargetType = entity.AuditSubjectUser
+	event.TargetID = event.ActorID
+	previous := user.Avatar
+
+	if err := change(ctx.Request.Context()); err != nil {
+		auditFailure(event, err)
+		c.auditService.Record(ctx.Request.Context(), event)
+		ctx.Error(err)
+		return
+	}
+	event.Changes = service.MarshalChanges(map[string]entity.AuditChange{
+		"avatar": {From: previous, To: user.Avatar},
+	})
+	c.auditService.Record(ctx.Request.Context(), event)
+
+	if _, err := setCacheValidators(ctx, user); err != nil {
+		ctx.Error(err)
+		return
+	}
 	response.Success(ctx, user)
 }
 
-// generateToken 生成JWT令牌
-func (c *UserController) generateToken(user *entity.User) (string, error) {
-	// 从配置中获取JWT密钥
-	cfg, err := config.LoadConfig("configs/config.yaml")
+// ConfirmEmail 使用发送到新邮箱的令牌确认修改邮箱，无需登录
+func (c *UserController) ConfirmEmail(ctx *gin.Context) {
+	var req ConfirmEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(validation.BindError(ctx, err))
+		return
+	}
+
+	event := newAuditEvent(ctx, entity.AuditActionEmailChange)
+	event.TargetType = entity.AuditSubjectUser
+
+	user, previous, err := c.userService.ConfirmEmailChange(ctx.Request.Context(), req.Token)
 	if err != nil {
-		return "", err
+		auditFailure(event, err)
+		c.auditService.Record(ctx.Request.Context(), event)
+		ctx.Error(err)
+		return
 	}
 
-	// 创建JWT声明
-	claims := jwt.MapClaims{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"exp":      time.Now().Add(time.Hour * time.Duration(cfg.JWT.Expire)).Unix(), // 从配置中获取过期时间
-		"iat":      time.Now().Unix(),
-		"iss":      cfg.JWT.Issuer, // 从配置中获取发行者
+	event.ActorType = entity.AuditSubjectUser
+	event.ActorID = strconv.FormatUint(uint64(user.ID), 10)
+	event.TargetID = event.ActorID
+	event.Changes = service.MarshalChanges(map[string]entity.AuditChange{
+		"email": {From: previous, To: user.Email},
+	})
+	c.auditService.Record(ctx.Request.Context(), event)
+
+	response.Success(ctx, gin.H{"user_id": user.ID, "email": user.Email})
+}
+
+// requestEmailChange 申请修改邮箱并记录审计事件，失败时已上报错误并返回false
+func (c *UserController) requestEmailChange(ctx *gin.Context, user *entity.User, email string) bool {
+	event := newAuditEvent(ctx, entity.AuditActionEmailChangeRequest)
+	event.ActorType = entity.AuditSubjectUser
+	event.ActorID = strconv.FormatUint(uint64(user.ID), 10)
+	event.TargetType = entity.AuditSubjectUser
+	event.TargetID = event.ActorID
+	event.Changes = service.MarshalChanges(map[string]entity.AuditChange{
+		"pending_email": {From: user.PendingEmail, To: email},
+	})
+
+	if err := c.userService.RequestEmailChange(ctx.Request.Context(), user, email, i18n.Locale(ctx)); err != nil {
+		auditFailure(event, err)
+		c.auditService.Record(ctx.Request.Context(), event)
+		ctx.Error(err)
+		return false
 	}
+	c.auditService.Record(ctx.Request.Context(), event)
+	return true
+}
 
-	// 创建令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+// saveProfile 只写入实际变更的字段并记录审计事件，没有变更时直接返回当前资料
+func (c *UserController) saveProfile(ctx *gin.Context, user *entity.User, changes map[string]entity.AuditChange) {
+	if len(changes) == 0 {
+		// 申请修改邮箱时资料已更新，需要重新计算ETag
+		if _, err := setCacheValidators(ctx, user); err != nil {
+			ctx.Error(err)
+			return
+		}
+		response.Success(ctx, user)
+		return
+	}
+
+	event := newAuditEvent(ctx, entity.AuditActionProfileUpdate)
+	event.ActorType = entity.AuditSubjectUser
+	event.ActorID = strconv.FormatUint(uint64(user.ID), 10)
+	event.TargetType = entity.AuditSubjectUser
+	event.TargetID = event.ActorID
+	event.Changes = service.MarshalChanges(changes)
+
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
 
-	// 签名令牌
-	tokenString, err := token.SignedString([]byte(cfg.JWT.Secret)) // 从配置中获取密钥
+	// 保存更新
+	if err := c.userService.UpdateUserFields(ctx.Request.Context(), user, fields...); err != nil {
+		auditFailure(event, err)
+		c.auditService.Record(ctx.Request.Context(), event)
+		ctx.Error(err)
+		return
+	}
+	c.auditService.Record(ctx.Request.Context(), event)
+
+	if _, err := setCacheValidators(ctx, user); err != nil {
+		ctx.Error(err)
+		return
+	}
+	response.Success(ctx, user)
+}
+
+// applyChange 值发生变化时修改字段，并记录变更前后的值；field同时是数据库列名
+func applyChange(changes map[string]entity.AuditChange, field string, target *string, value string) {
+	if value == *target {
+		return
+	}
+	changes[field] = entity.AuditChange{From: *target, To: value}
+	*target = value
+}
+
+// setCacheValidators 计算用户资料的ETag，并设置ETag和Last-Modified响应头
+func setCacheValidators(ctx *gin.Context, user *entity.User) (string, error) {
+	etag, err := httpcache.ETag(user)
 	if err != nil {
 		return "", err
 	}
-
-	return tokenString, nil
+	httpcache.SetHeaders(ctx.Writer, etag, user.UpdatedAt)
+	return etag, nil
 }
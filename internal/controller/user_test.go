@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/repository"
+	"gin-server-template/pkg/mergepatch"
+	"gin-server-template/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPatchProfile(t *testing.T) {
+	user := &entity.User{Username: "patch_user", Email: "patch_user@example.com", Password: "x", Nickname: "alice", Avatar: "https://cdn.example.com/a.png"}
+	if err := repository.NewUserRepository().Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	router := newUserTestRouter(user.ID)
+
+	t.Run("普通JSON", func(t *testing.T) {
+		w := serveJSON(router, http.MethodPatch, "/profile", "application/json", `{"nickname": "bob"}`, nil)
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Fatalf("status = %d, want 415, body = %s", w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Accept-Patch"); got != mergepatch.ContentType {
+			t.Errorf("Accept-Patch = %q, want %q", got, mergepatch.ContentType)
+		}
+	})
+
+	t.Run("不允许修改的字段", func(t *testing.T) {
+		w := serveJSON(router, http.MethodPatch, "/profile", mergepatch.ContentType, `{"username": "root", "avatar": null, "nickname": "bob"}`, nil)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400, body = %s", w.Code, w.Body.String())
+		}
+		// 头像只能通过上传接口设置
+		for _, field := range []string{`"username"`, `"avatar"`} {
+			if !strings.Contains(w.Body.String(), field) {
+				t.Errorf("body = %s, want %s not_allowed", w.Body.String(), field)
+			}
+		}
+		if !strings.Contains(w.Body.String(), `"not_allowed"`) {
+			t.Errorf("body = %s, want not_allowed", w.Body.String())
+		}
+	})
+
+	t.Run("校验失败", func(t *testing.T) {
+		w := serveJSON(router, http.MethodPatch, "/profile", mergepatch.ContentType, `{"email": null}`, nil)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400, body = %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("If-Match不一致", func(t *testing.T) {
+		w := serveJSON(router, http.MethodPatch, "/profile", mergepatch.ContentType, `{"nickname": "bob"}`, map[string]string{"If-Match": `"stale"`})
+		if w.Code != http.StatusPreconditionFailed {
+			t.Fatalf("status = %d, want 412, body = %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("合并补丁", func(t *testing.T) {
+		w := serveJSON(router, http.MethodPatch, "/profile", mergepatch.ContentType+"; charset=utf-8", `{"nickname": null}`, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200, body = %s", w.Code, w.Body.String())
+		}
+		var body struct {
+			Data entity.User `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.Nickname != "" || body.Data.Avatar != user.Avatar || body.Data.Email != user.Email {
+			t.Errorf("资料 = %+v", body.Data)
+		}
+		if body.Data.Version != user.Version+1 {
+			t.Errorf("Version = %d, want %d", body.Data.Version, user.Version+1)
+		}
+		if w.Header().Get("ETag") == "" {
+			t.Error("应返回新的ETag")
+		}
+	})
+}
+
+func TestRegisterPasswordLength(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(response.ErrorHandler(response.ErrorOptions{}))
+	router.POST("/register", NewUserController().Register)
+
+	tests := []struct {
+		name     string
+		password string
+		rule     string
+	}{
+		// 9个字节但只有5个字符
+		{"字符数不足", "Ab1密码", "strong_password"},
+		// 27个字符但有73个字节，超出bcrypt的处理上限
+		{"字节数超限", "Aa1!" + strings.Repeat("密", 23), "max_bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{"username": "length_user", "email": "length_user@example.com", "password": tt.password})
+			w := serveJSON(router, http.MethodPost, "/register", "application/json", string(body), nil)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400, body = %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `"rule":"`+tt.rule+`"`) {
+				t.Errorf("body = %s, want rule %s", w.Body.String(), tt.rule)
+			}
+		})
+	}
+}
+
+// newUserTestRouter 创建以指定用户身份访问个人资料接口的路由
+func newUserTestRouter(userID uint) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	c := NewUserController()
+
+	router := gin.New()
+	router.Use(response.ErrorHandler(response.ErrorOptions{}), func(ctx *gin.Context) {
+		ctx.Set("userID", userID)
+		ctx.Next()
+	})
+	router.GET("/profile", c.GetProfile)
+	router.PUT("/profile", c.UpdateProfile)
+	router.PATCH("/profile", c.PatchProfile)
+	return router
+}
+
+// serveJSON 发送带请求体的请求
+func serveJSON(router http.Handler, method, path, contentType, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"gin-server-template/pkg/requestid"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// registerRequestIDComment 注册GORM回调，在SQL语句前附加请求ID注释
+//
+// 生成的语句形如 /* request_id=... */ SELECT ...，便于在慢查询日志和数据库审计中关联请求。
+func registerRequestIDComment(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Query().Before("gorm:query").Register("request_id:query", commentClause("SELECT")); err != nil {
+		return err
+	}
+	if err := callback.Create().Before("gorm:create").Register("request_id:create", commentClause("INSERT")); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("request_id:update", commentClause("UPDATE")); err != nil {
+		return err
+	}
+	return callback.Delete().Before("gorm:delete").Register("request_id:delete", commentClause("DELETE"))
+}
+
+// commentClause 返回为指定子句添加请求ID注释的回调，请求ID已通过格式校验，可以安全拼接
+func commentClause(clauseName string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		id := requestid.FromContext(tx.Statement.Context)
+		if id == "" {
+			return
+		}
+
+		c := tx.Statement.Clauses[clauseName]
+		c.Name = clauseName
+		c.BeforeExpression = clause.Expr{SQL: "/* request_id=" + id + " */"}
+		tx.Statement.Clauses[clauseName] = c
+	}
+}
@@ -0,0 +1,4 @@
+package database
+
+// RegisterTracing 供外部测试包为测试用的GORM实例注册追踪回调
+var RegisterTracing = registerTracing
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// Ping 检查指定驱动的数据库连接是否可用，未使用数据库的驱动（如mock）直接返回nil
+func Ping(ctx context.Context, driver string) error {
+	switch driver {
+	case "mysql":
+		if DB == nil {
+			return errors.New("MySQL连接未初始化")
+		}
+		sqlDB, err := DB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+
+	case "mongodb":
+		if MongoDB == nil {
+			return errors.New("MongoDB连接未初始化")
+		}
+		return MongoDB.Ping(ctx, readpref.Primary())
+
+	default:
+		return nil
+	}
+}
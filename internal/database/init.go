@@ -4,7 +4,7 @@ import (
 	"errors"
 	"gin-server-template/internal/config"
 	"gin-server-template/internal/entity"
-	"log"
+	"log/slog"
 )
 
 // InitDatabase 初始化数据库
@@ -35,7 +35,7 @@ func InitDatabase(cfg *config.DatabaseConfig) error {
 		return errors.New("不支持的数据库类型: " + cfg.Driver)
 	}
 
-	log.Println("数据库初始化成功")
+	slog.Info("数据库初始化成功", "driver", cfg.Driver)
 	return nil
 }
 
@@ -44,6 +44,11 @@ func AutoMigrate() error {
 	// 在这里添加需要迁移的模型
 	return DB.AutoMigrate(
 		&entity.User{},
+		&entity.OAuthClient{},
+		&entity.OAuthAuthorizationCode{},
+		&entity.RevokedToken{},
+		&entity.AuditEvent{},
+		&entity.EmailChange{},
 		// 其他模型...
 	)
 }
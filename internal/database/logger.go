@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin-server-template/pkg/logger"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger 将GORM日志桥接到slog，SQL语句以debug级别输出
+type gormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// newGormLogger 创建GORM日志桥接器
+func newGormLogger(slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{
+		level:         gormlogger.Info,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// LogMode 设置日志级别
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info 输出info级别日志
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logger.FromContext(ctx).InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn 输出warn级别日志
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logger.FromContext(ctx).WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error 输出error级别日志
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logger.FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace 记录SQL执行情况：出错时为error，慢SQL为warn，其余为debug
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("elapsed", elapsed),
+	}
+
+	log := logger.FromContext(ctx)
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		log.LogAttrs(ctx, slog.LevelError, "SQL执行失败", append(attrs, slog.String("error", err.Error()))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		log.LogAttrs(ctx, slog.LevelWarn, "慢SQL", attrs...)
+	case l.level >= gormlogger.Info:
+		log.LogAttrs(ctx, slog.LevelDebug, "SQL", attrs...)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"gin-server-template/internal/config"
-	"log"
+	"gin-server-template/internal/metrics"
+	"log/slog"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -34,6 +36,12 @@ func InitMongoDB(cfg *config.DatabaseConfig) error {
 	clientOptions.SetMinPoolSize(uint64(cfg.MaxIdleConns))
 	clientOptions.SetMaxConnIdleTime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
 
+	// 连接池事件转换为指标
+	clientOptions.SetPoolMonitor(metrics.NewMongoPoolMonitor())
+
+	// 为命令创建追踪span
+	clientOptions.SetMonitor(newMongoCommandMonitor())
+
 	// 创建连接上下文
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -49,11 +57,53 @@ func InitMongoDB(cfg *config.DatabaseConfig) error {
 		return err
 	}
 
+	// 创建唯一索引，保证重复数据能以duplicate key错误的形式返回
+	if err = ensureMongoIndexes(ctx, client.Database(cfg.DBName)); err != nil {
+		return err
+	}
+
 	// 保存客户端实例和数据库名称
 	MongoDB = client
 	MongoDBName = cfg.DBName
 
-	log.Println("MongoDB连接成功")
+	slog.Info("MongoDB连接成功")
+	return nil
+}
+
+// ensureMongoIndexes 创建各集合所需的唯一索引，并为有有效期的记录创建TTL索引
+func ensureMongoIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string][]string{
+		"users":                     {"username", "email"},
+		"oauth_clients":             {"clientid"},
+		"oauth_authorization_codes": {"codehash"},
+		"revoked_tokens":            {"jti"},
+		"email_changes":             {"tokenhash"},
+	}
+
+	for collection, fields := range indexes {
+		models := make([]mongo.IndexModel, 0, len(fields))
+		for _, field := range fields {
+			models = append(models, mongo.IndexModel{
+				Keys:    bson.D{{Key: field, Value: 1}},
+				Options: options.Index().SetUnique(true),
+			})
+		}
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("创建%s集合索引失败: %w", collection, err)
+		}
+	}
+
+	// 未兑换的授权码和撤销记录过期后由MongoDB自动删除
+	for _, collection := range []string{"oauth_authorization_codes", "revoked_tokens"} {
+		model := mongo.IndexModel{
+			Keys:    bson.D{{Key: "expiresat", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		}
+		if _, err := db.Collection(collection).Indexes().CreateOne(ctx, model); err != nil {
+			return fmt.Errorf("创建%s集合TTL索引失败: %w", collection, err)
+		}
+	}
+
 	return nil
 }
 
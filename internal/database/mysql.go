@@ -3,13 +3,11 @@ package database
 import (
 	"fmt"
 	"gin-server-template/internal/config"
-	"log"
-	"os"
+	"gin-server-template/internal/metrics"
 	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
@@ -25,25 +23,26 @@ func InitMySQL(cfg *config.DatabaseConfig) error {
 		cfg.Charset,
 	)
 
-	// 配置GORM日志
-	logConfig := logger.Config{
-		SlowThreshold: time.Second, // 慢SQL阈值
-		LogLevel:      logger.Info, // 日志级别
-		Colorful:      true,        // 彩色打印
-	}
-
-	// 初始化连接
+	// 初始化连接，GORM日志桥接到slog
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.New(
-			log.New(os.Stdout, "\r\n", log.LstdFlags), // 使用标准日志库
-			logConfig,
-		),
+		Logger:         newGormLogger(time.Second), // 慢SQL阈值
+		TranslateError: true,                       // 将唯一约束冲突等驱动错误转换为gorm.ErrDuplicatedKey
 	})
 
 	if err != nil {
 		return err
 	}
 
+	// 在SQL中附加请求ID注释
+	if err := registerRequestIDComment(db); err != nil {
+		return err
+	}
+
+	// 为SQL创建追踪span
+	if err := registerTracing(db); err != nil {
+		return err
+	}
+
 	// 获取通用数据库对象，设置连接池参数
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -55,6 +54,11 @@ func InitMySQL(cfg *config.DatabaseConfig) error {
 	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns) // 最大连接数
 	sqlDB.SetConnMaxLifetime(time.Hour)     // 连接最大生命周期
 
+	// 导出连接池状态指标
+	if err := metrics.RegisterDBStats(sqlDB, cfg.DBName); err != nil {
+		return err
+	}
+
 	DB = db
 	return nil
 }
@@ -0,0 +1,166 @@
+package database_test
+
+import (
+	"context"
+	"fmt"
+	"gin-server-template/internal/database"
+	"gin-server-template/internal/middleware"
+	"gin-server-template/internal/service"
+	"gin-server-template/pkg/tracing"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// TestMain 仓库按工作目录下的configs/config.yaml选择实现，测试在临时目录中使用MySQL实现
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "database-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := func() int {
+		defer os.RemoveAll(dir)
+		if err := os.MkdirAll(filepath.Join(dir, "configs"), 0o755); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := os.WriteFile(filepath.Join(dir, "configs", "config.yaml"), []byte("database:\n  driver: mysql\n"), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := os.Chdir(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return m.Run()
+	}()
+	os.Exit(code)
+}
+
+// TestTracingSpanTree 检查一次请求产生的HTTP、服务和数据库span的父子关系
+//
+// GORM以DryRun模式运行，只生成SQL而不连接数据库，追踪回调与真实查询时相同。
+func TestTracingSpanTree(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown := tracing.SetupWithExporter(tracing.Options{ServiceName: "test", SampleRatio: 1}, keepSpans{exporter})
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "user:password@tcp(127.0.0.1:3306)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.RegisterTracing(db); err != nil {
+		t.Fatal(err)
+	}
+	database.DB = db
+	t.Cleanup(func() { database.DB = nil })
+
+	userService := service.NewUserService()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Tracing())
+	router.GET("/users/:id", func(c *gin.Context) {
+		id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+		if _, err := userService.GetUserByID(c.Request.Context(), uint(id)); err != nil {
+			c.Error(err)
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	// 上游传入的追踪上下文应被沿用
+	const (
+		upstreamTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		upstreamSpanID  = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("traceparent", "00-"+upstreamTraceID+"-"+upstreamSpanID+"-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// 关闭时刷新批量导出器中的span
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	httpSpan, ok := spans["GET /users/:id"]
+	if !ok {
+		t.Fatalf("缺少HTTP span，导出的span: %v", spanNames(exporter.GetSpans()))
+	}
+	serviceSpan, ok := spans["UserService.GetUserByID"]
+	if !ok {
+		t.Fatalf("缺少服务span，导出的span: %v", spanNames(exporter.GetSpans()))
+	}
+	dbSpan, ok := spans["mysql.query"]
+	if !ok {
+		t.Fatalf("缺少数据库span，导出的span: %v", spanNames(exporter.GetSpans()))
+	}
+
+	if got := httpSpan.SpanContext.TraceID().String(); got != upstreamTraceID {
+		t.Errorf("trace ID = %s, want %s", got, upstreamTraceID)
+	}
+	if got := httpSpan.Parent.SpanID().String(); got != upstreamSpanID {
+		t.Errorf("HTTP span的父span = %s, want %s", got, upstreamSpanID)
+	}
+	assertChild(t, httpSpan, serviceSpan)
+	assertChild(t, serviceSpan, dbSpan)
+
+	if httpSpan.SpanKind != trace.SpanKindServer || dbSpan.SpanKind != trace.SpanKindClient {
+		t.Errorf("span kind = %v, %v", httpSpan.SpanKind, dbSpan.SpanKind)
+	}
+	var query string
+	for _, attr := range dbSpan.Attributes {
+		if attr.Key == "db.query.text" {
+			query = attr.Value.AsString()
+		}
+	}
+	if !strings.HasPrefix(query, "SELECT") {
+		t.Errorf("db.query.text = %q", query)
+	}
+}
+
+// keepSpans 关闭时保留已导出的span，内存导出器的Shutdown会清空span
+type keepSpans struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepSpans) Shutdown(context.Context) error {
+	return nil
+}
+
+// assertChild 检查child是否为parent的直接子span
+func assertChild(t *testing.T, parent, child tracetest.SpanStub) {
+	t.Helper()
+
+	if child.SpanContext.TraceID() != parent.SpanContext.TraceID() {
+		t.Errorf("%s与%s不在同一条链路中", child.Name, parent.Name)
+	}
+	if child.Parent.SpanID() != parent.SpanContext.SpanID() {
+		t.Errorf("%s的父span = %s, want %s (%s)", child.Name, child.Parent.SpanID(), parent.SpanContext.SpanID(), parent.Name)
+	}
+}
+
+// spanNames 返回span名称列表，用于失败时输出
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
@@ -0,0 +1,70 @@
+package entity
+
+import "time"
+
+// 审计事件类型
+const (
+	AuditActionRegister           = "user.register"
+	AuditActionLogin              = "user.login"
+	AuditActionProfileUpdate      = "user.profile_update"
+	AuditActionPasswordChange     = "user.password_change"
+	AuditActionEmailChangeRequest = "user.email_change_request"
+	AuditActionEmailChange        = "user.email_change"
+	AuditActionTokenRevoke        = "oauth.token_revoke"
+	AuditActionAdminAccess        = "admin.access"
+)
+
+// 审计事件的操作者和目标类型
+const (
+	AuditSubjectUser   = "user"
+	AuditSubjectClient = "client"
+	AuditSubjectAdmin  = "admin"
+	AuditSubjectToken  = "token"
+	AuditSubjectPath   = "path"
+)
+
+// AuditEvent 审计事件实体，记录后不再修改
+type AuditEvent struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Action     string    `json:"action" gorm:"size:64;not null;index"`
+	Success    bool      `json:"success"`
+	Reason     string    `json:"reason,omitempty" gorm:"size:255"` // 失败原因
+	ActorType  string    `json:"actor_type" gorm:"size:32"`
+	ActorID    string    `json:"actor_id" gorm:"size:64;index"` // 匿名操作为空
+	TargetType string    `json:"target_type,omitempty" gorm:"size:32"`
+	TargetID   string    `json:"target_id,omitempty" gorm:"size:255;index"`
+	Changes    string    `json:"changes,omitempty" gorm:"type:text"` // 变更字段的JSON，格式为{"字段":{"from":旧值,"to":新值}}
+	IP         string    `json:"ip" gorm:"size:45"`
+	UserAgent  string    `json:"user_agent" gorm:"size:255"`
+	RequestID  string    `json:"request_id" gorm:"size:64;index"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName 指定表名
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditFilter 审计事件查询条件，零值字段不参与过滤
+type AuditFilter struct {
+	Action    string
+	ActorID   string
+	TargetID  string
+	RequestID string
+	Success   *bool
+	From      time.Time
+	To        time.Time
+	Page      int // 从1开始
+	PageSize  int
+}
+
+// Offset 返回分页查询的偏移量
+func (f AuditFilter) Offset() int {
+	return (f.Page - 1) * f.PageSize
+}
+
+// AuditChange 单个字段的变更
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// EmailChange 待确认的邮箱修改请求
+//
+// 确认令牌发送到新邮箱，只保存其SHA-256摘要；令牌只能使用一次，确认后新邮箱才会生效。
+type EmailChange struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Email     string    `json:"email" gorm:"size:100;not null"`        // 待确认的新邮箱
+	TokenHash string    `json:"-" gorm:"size:64;not null;uniqueIndex"` // 确认令牌的SHA-256摘要
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (EmailChange) TableName() string {
+	return "email_changes"
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "errors"
+
+// 数据访问层返回的领域错误，各仓库实现需将底层数据库错误转换为以下错误
+var (
+	// ErrNotFound 记录不存在
+	ErrNotFound = errors.New("记录不存在")
+
+	// ErrDuplicate 违反唯一约束
+	ErrDuplicate = errors.New("记录已存在")
+
+	// ErrConflict 记录已被并发修改
+	ErrConflict = errors.New("记录已被修改")
+)
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// OAuthClient OAuth2客户端实体
+type OAuthClient struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ClientID     string    `json:"client_id" gorm:"size:64;not null;uniqueIndex"`
+	ClientSecret string    `json:"-" gorm:"size:255"` // 哈希后的客户端密钥，公共客户端为空
+	Name         string    `json:"name" gorm:"size:100;not null"`
+	RedirectURIs string    `json:"redirect_uris" gorm:"size:1024"` // 以空格分隔
+	GrantTypes   string    `json:"grant_types" gorm:"size:255"`    // 以空格分隔
+	Scopes       string    `json:"scopes" gorm:"size:255"`         // 以空格分隔
+	Confidential bool      `json:"confidential"`
+	OwnerID      uint      `json:"owner_id" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// HasRedirectURI 检查回调地址是否已登记
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	return containsField(c.RedirectURIs, uri)
+}
+
+// HasGrantType 检查客户端是否允许使用指定的授权类型
+func (c *OAuthClient) HasGrantType(grantType string) bool {
+	return containsField(c.GrantTypes, grantType)
+}
+
+// AllowsScope 检查请求的权限范围是否都在客户端允许范围内
+func (c *OAuthClient) AllowsScope(scope string) bool {
+	for _, s := range strings.Fields(scope) {
+		if !containsField(c.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// OAuthAuthorizationCode OAuth2授权码实体
+type OAuthAuthorizationCode struct {
+	ID                  uint      `json:"id" gorm:"primaryKey"`
+	CodeHash            string    `json:"-" gorm:"size:64;not null;uniqueIndex"` // 授权码的SHA-256摘要
+	ClientID            string    `json:"client_id" gorm:"size:64;not null"`
+	UserID              uint      `json:"user_id" gorm:"not null"`
+	RedirectURI         string    `json:"redirect_uri" gorm:"size:255"`
+	Scope               string    `json:"scope" gorm:"size:255"`
+	CodeChallenge       string    `json:"-" gorm:"size:128"`
+	CodeChallengeMethod string    `json:"-" gorm:"size:10"`
+	ExpiresAt           time.Time `json:"expires_at" gorm:"index"` // 过期的授权码会被定期清理
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// RevokedToken 已撤销的令牌
+type RevokedToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	JTI       string    `json:"jti" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"` // 令牌本身的过期时间，之后撤销记录会被清理
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// containsField 检查以空格分隔的列表中是否包含指定值
+func containsField(list, value string) bool {
+	for _, item := range strings.Fields(list) {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
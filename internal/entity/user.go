@@ -6,15 +6,18 @@ import (
 
 // User 用户实体
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Username  string    `json:"username" gorm:"size:50;not null;uniqueIndex"`
-	Email     string    `json:"email" gorm:"size:100;uniqueIndex"`
-	Password  string    `json:"-" gorm:"size:100;not null"`
-	Nickname  string    `json:"nickname" gorm:"size:50"`
-	Avatar    string    `json:"avatar" gorm:"size:255"`
-	Status    int       `json:"status" gorm:"default:1"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Username     string    `json:"username" gorm:"size:50;not null;uniqueIndex"`
+	Email        string    `json:"email" gorm:"size:100;uniqueIndex"`
+	PendingEmail string    `json:"pending_email,omitempty" gorm:"size:100"` // 已申请修改、等待确认的新邮箱
+	Password     string    `json:"-" gorm:"size:255;not null"`
+	Nickname     string    `json:"nickname" gorm:"size:50"`
+	Avatar       string    `json:"avatar" gorm:"size:255"`
+	AvatarKeys   string    `json:"-" gorm:"size:1024"` // 上传的头像各尺寸在对象存储中的键，空格分隔
+	Status       int       `json:"status" gorm:"default:1"`
+	Version      uint      `json:"version" gorm:"not null;default:1"` // 乐观锁版本号，每次更新加1
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // TableName 指定表名
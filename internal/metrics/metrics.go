@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// HTTP指标
+var (
+	// HTTPRequestsTotal HTTP请求总数，route为路由模板，未匹配的请求为空字符串
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP请求总数",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration HTTP请求处理耗时
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP请求处理耗时（秒）",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestsInFlight 正在处理的HTTP请求数
+	HTTPRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "正在处理的HTTP请求数",
+	})
+
+	// HTTPPanicsTotal 处理请求时发生panic的次数，route为路由模板
+	HTTPPanicsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_panics_total",
+		Help: "处理HTTP请求时发生panic的次数",
+	}, []string{"route"})
+)
+
+// 业务指标
+var (
+	// UserRegistrationsTotal 注册成功的用户数
+	UserRegistrationsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "user_registrations_total",
+		Help: "注册成功的用户数",
+	})
+
+	// UserLoginsTotal 登录次数，result为success或failure
+	UserLoginsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "user_logins_total",
+		Help: "用户登录次数",
+	}, []string{"result"})
+
+	// AuthTokenFailuresTotal JWT认证失败次数，reason为失败原因
+	AuthTokenFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_token_failures_total",
+		Help: "JWT认证失败次数",
+	}, []string{"reason"})
+
+	// RateLimitRejectionsTotal 被限流拒绝的请求数，policy为限流策略名称
+	RateLimitRejectionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_rejections_total",
+		Help: "被限流拒绝的请求数",
+	}, []string{"policy"})
+)
+
+// 登录结果标签
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+)
+
+// Handler 返回暴露指标的HTTP处理器
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDBStats 注册sql.DBStats采集器，导出MySQL连接池状态
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, dbName))
+}
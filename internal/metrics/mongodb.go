@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoDB连接池指标
+var (
+	// MongoPoolEventsTotal 连接池事件数，event为驱动定义的事件类型
+	MongoPoolEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongodb_pool_events_total",
+		Help: "MongoDB连接池事件数",
+	}, []string{"event"})
+
+	// MongoPoolConnections 连接池中已建立的连接数
+	MongoPoolConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mongodb_pool_connections",
+		Help: "MongoDB连接池中已建立的连接数",
+	})
+
+	// MongoPoolConnectionsInUse 已被取出正在使用的连接数
+	MongoPoolConnectionsInUse = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mongodb_pool_connections_in_use",
+		Help: "MongoDB连接池中正在使用的连接数",
+	})
+)
+
+// NewMongoPoolMonitor 创建MongoDB连接池监视器，将连接池事件转换为指标
+func NewMongoPoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			MongoPoolEventsTotal.WithLabelValues(e.Type).Inc()
+
+			switch e.Type {
+			case event.ConnectionCreated:
+				MongoPoolConnections.Inc()
+			case event.ConnectionClosed:
+				MongoPoolConnections.Dec()
+			case event.GetSucceeded:
+				MongoPoolConnectionsInUse.Inc()
+			case event.ConnectionReturned:
+				MongoPoolConnectionsInUse.Dec()
+			}
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/service"
+	"gin-server-template/pkg/response"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth 管理接口认证中间件，校验请求头Authorization: Bearer <token>或X-Admin-Token
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-Admin-Token")
+		if provided == "" {
+			provided = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+
+		// 使用常量时间比较，避免通过响应时间猜测令牌
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.Error(response.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AdminAudit 管理接口审计中间件，记录每次管理接口访问，包括认证失败的访问
+//
+// 需要注册在response.ErrorHandler之前，以便记录错误响应最终的状态码。
+func AdminAudit() gin.HandlerFunc {
+	auditService := service.NewAuditService()
+
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		event := &entity.AuditEvent{
+			Action:     entity.AuditActionAdminAccess,
+			Success:    status < http.StatusBadRequest,
+			ActorType:  entity.AuditSubjectAdmin,
+			TargetType: entity.AuditSubjectPath,
+			TargetID:   c.Request.Method + " " + c.Request.URL.Path,
+			IP:         c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+		}
+		if !event.Success {
+			event.Reason = http.StatusText(status)
+		}
+		auditService.Record(c.Request.Context(), event)
+	}
+}
@@ -1,73 +1,172 @@
 package middleware
 
 import (
+	"fmt"
 	"gin-server-template/internal/config"
+	"gin-server-template/internal/metrics"
+	"gin-server-template/internal/service"
 	"gin-server-template/pkg/response"
+	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTAuth JWT认证中间件
+// 认证模块业务错误码
+const (
+	CodeTokenMissing           = 20001
+	CodeTokenMalformed         = 20002
+	CodeTokenInvalid           = 20003
+	CodeTokenClaimsInvalid     = 20004
+	CodeTokenUserInvalid       = 20005
+	CodeTokenRevoked           = 20006
+	CodeTokenScopeInsufficient = 20007
+)
+
+// 认证中间件返回的错误
+var (
+	ErrTokenMissing           = response.NewError(http.StatusUnauthorized, CodeTokenMissing, "未提供认证令牌")
+	ErrTokenMalformed         = response.NewError(http.StatusUnauthorized, CodeTokenMalformed, "认证令牌格式错误")
+	ErrTokenInvalid           = response.NewError(http.StatusUnauthorized, CodeTokenInvalid, "无效的认证令牌")
+	ErrTokenClaimsInvalid     = response.NewError(http.StatusUnauthorized, CodeTokenClaimsInvalid, "无效的令牌声明")
+	ErrTokenUserInvalid       = response.NewError(http.StatusUnauthorized, CodeTokenUserInvalid, "无效的用户信息")
+	ErrTokenRevoked           = response.NewError(http.StatusUnauthorized, CodeTokenRevoked, "认证令牌已被撤销")
+	ErrTokenScopeInsufficient = response.NewError(http.StatusForbidden, CodeTokenScopeInsufficient, "令牌的权限范围不足")
+)
+
+// JWTAuth JWT认证中间件，只接受登录签发的令牌，OAuth2访问令牌需通过OAuthAuth认证
 func JWTAuth() gin.HandlerFunc {
+	tokenService := service.NewTokenService()
+
 	return func(c *gin.Context) {
-		// 从请求头获取token
-		authorization := c.GetHeader("Authorization")
-		if authorization == "" {
-			response.Unauthorized(c, "未提供认证令牌")
-			c.Abort()
+		claims, ok := authenticate(c, tokenService, service.TokenTypeUser)
+		if !ok {
 			return
 		}
 
-		// 检查Bearer前缀
-		parts := strings.SplitN(authorization, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			response.Unauthorized(c, "认证令牌格式错误")
-			c.Abort()
+		// 将用户ID存储在上下文中
+		userID, ok := claims["user_id"].(float64)
+		if !ok {
+			abortAuth(c, ErrTokenUserInvalid, "user_invalid")
 			return
 		}
 
-		// 解析JWT令牌
-		tokenString := parts[1]
-
-		// 从应用配置获取JWT密钥
-		cfg, err := config.LoadConfig("configs/config.yaml")
-		if err != nil {
-			response.ServerError(c, "服务器配置错误")
-			c.Abort()
-			return
-		}
+		// 将用户ID设置到上下文中，供后续处理器使用
+		c.Set("userID", uint(userID))
+		withLogAttrs(c, "user_id", uint(userID))
+		c.Next()
+	}
+}
 
-		// 解析和验证令牌
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(cfg.JWT.Secret), nil
-		})
+// OAuthAuth OAuth2访问令牌认证中间件，令牌的权限范围必须包含scope
+//
+// 客户端ID和权限范围分别存入上下文的clientID和scope；授权码模式签发的令牌还会存入userID，
+// 客户端凭证模式签发的令牌没有用户。
+func OAuthAuth(scope string) gin.HandlerFunc {
+	tokenService := service.NewTokenService()
 
-		if err != nil || !token.Valid {
-			response.Unauthorized(c, "无效的认证令牌")
-			c.Abort()
+	return func(c *gin.Context) {
+		claims, ok := authenticate(c, tokenService, service.TokenTypeAccess)
+		if !ok {
 			return
 		}
 
-		// 从令牌中提取声明
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			response.Unauthorized(c, "无效的令牌声明")
-			c.Abort()
+		clientID, _ := claims["client_id"].(string)
+		granted, _ := claims["scope"].(string)
+		if clientID == "" {
+			abortAuth(c, ErrTokenClaimsInvalid, "claims_invalid")
 			return
 		}
-
-		// 将用户ID存储在上下文中
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			response.Unauthorized(c, "无效的用户信息")
-			c.Abort()
+		if !slices.Contains(strings.Fields(granted), scope) {
+			c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, scope))
+			abortAuth(c, ErrTokenScopeInsufficient, "insufficient_scope")
 			return
 		}
 
-		// 将用户ID设置到上下文中，供后续处理器使用
-		c.Set("userID", uint(userID))
+		c.Set("clientID", clientID)
+		c.Set("scope", granted)
+		if userID, ok := claims["user_id"].(float64); ok {
+			c.Set("userID", uint(userID))
+			withLogAttrs(c, "user_id", uint(userID), "client_id", clientID)
+		} else {
+			withLogAttrs(c, "client_id", clientID)
+		}
 		c.Next()
 	}
 }
+
+// authenticate 从Authorization请求头解析令牌，校验签名、有效期、类型和撤销状态
+//
+// 校验失败时中止请求并返回false。
+func authenticate(c *gin.Context, tokenService *service.TokenService, tokenType string) (jwt.MapClaims, bool) {
+	// 从请求头获取token
+	authorization := c.GetHeader("Authorization")
+	if authorization == "" {
+		abortAuth(c, ErrTokenMissing, "missing")
+		return nil, false
+	}
+
+	// 检查Bearer前缀
+	parts := strings.SplitN(authorization, " ", 2)
+	if !(len(parts) == 2 && parts[0] == "Bearer") {
+		abortAuth(c, ErrTokenMalformed, "malformed")
+		return nil, false
+	}
+
+	// 解析JWT令牌
+	tokenString := parts[1]
+
+	// 从应用配置获取JWT密钥
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		c.Error(err)
+		c.Abort()
+		return nil, false
+	}
+
+	// 解析和验证令牌，只接受签发时使用的HS256算法
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte(cfg.JWT.Secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	if err != nil || !token.Valid {
+		abortAuth(c, ErrTokenInvalid, "invalid")
+		return nil, false
+	}
+
+	// 从令牌中提取声明
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		abortAuth(c, ErrTokenClaimsInvalid, "claims_invalid")
+		return nil, false
+	}
+
+	// 登录令牌和OAuth2访问令牌使用同一密钥签名，按typ声明区分
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		abortAuth(c, ErrTokenInvalid, "wrong_type")
+		return nil, false
+	}
+
+	// 检查令牌是否已被撤销
+	revoked, err := tokenService.IsRevoked(c.Request.Context(), claims)
+	if err != nil {
+		c.Error(err)
+		c.Abort()
+		return nil, false
+	}
+	if revoked {
+		abortAuth(c, ErrTokenRevoked, "revoked")
+		return nil, false
+	}
+
+	return claims, true
+}
+
+// abortAuth 记录认证失败指标并中止请求
+func abortAuth(c *gin.Context, err *response.Error, reason string) {
+	metrics.AuthTokenFailuresTotal.WithLabelValues(reason).Inc()
+	c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"encoding/json"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/service"
+	"gin-server-template/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTAuth(t *testing.T) {
+	tokenService := service.NewTokenService()
+	user := &entity.User{ID: 7, Username: "alice"}
+	client := &entity.OAuthClient{ClientID: "client"}
+
+	userToken, err := tokenService.GenerateUserToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessToken, _, err := tokenService.GenerateAccessToken(user, client, "profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs384Token := signTestToken(t, jwt.SigningMethodHS384, jwt.MapClaims{"typ": service.TokenTypeUser, "user_id": 7})
+
+	router := newAuthTestRouter(JWTAuth())
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+		code   int
+	}{
+		{"登录令牌", userToken, http.StatusOK, 0},
+		{"OAuth2访问令牌", accessToken, http.StatusUnauthorized, CodeTokenInvalid},
+		{"其他签名算法", hs384Token, http.StatusUnauthorized, CodeTokenInvalid},
+		{"缺少令牌", "", http.StatusUnauthorized, CodeTokenMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveWithToken(router, tt.token)
+			assertAuthResponse(t, w, tt.status, tt.code)
+			if tt.status == http.StatusOK && w.Body.String() != "7" {
+				t.Errorf("userID = %s, want 7", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestOAuthAuth(t *testing.T) {
+	tokenService := service.NewTokenService()
+	user := &entity.User{ID: 7, Username: "alice"}
+	client := &entity.OAuthClient{ClientID: "client"}
+
+	userToken, err := tokenService.GenerateUserToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profileToken, _, err := tokenService.GenerateAccessToken(user, client, "profile email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	emailToken, _, err := tokenService.GenerateAccessToken(user, client, "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := newAuthTestRouter(OAuthAuth(service.ScopeProfile))
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+		code   int
+	}{
+		{"包含所需权限范围", profileToken, http.StatusOK, 0},
+		{"权限范围不足", emailToken, http.StatusForbidden, CodeTokenScopeInsufficient},
+		{"登录令牌", userToken, http.StatusUnauthorized, CodeTokenInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveWithToken(router, tt.token)
+			assertAuthResponse(t, w, tt.status, tt.code)
+			if tt.status == http.StatusForbidden && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("权限范围不足时应返回WWW-Authenticate")
+			}
+		})
+	}
+}
+
+// newAuthTestRouter 创建使用指定认证中间件的路由，处理器返回上下文中的用户ID
+func newAuthTestRouter(auth gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(response.ErrorHandler(response.ErrorOptions{}))
+	router.GET("/", auth, func(c *gin.Context) {
+		c.String(http.StatusOK, "%v", c.GetUint("userID"))
+	})
+	return router
+}
+
+// serveWithToken 携带Bearer令牌发送请求
+func serveWithToken(router http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// assertAuthResponse 检查响应状态码和业务错误码
+func assertAuthResponse(t *testing.T, w *httptest.ResponseRecorder, status, code int) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d, body = %s", w.Code, status, w.Body.String())
+	}
+	if code == 0 {
+		return
+	}
+	var body struct {
+		Code int `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if body.Code != code {
+		t.Errorf("code = %d, want %d", body.Code, code)
+	}
+}
+
+// signTestToken 使用测试密钥签名令牌
+func signTestToken(t *testing.T, method jwt.SigningMethod, claims jwt.MapClaims) string {
+	t.Helper()
+
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, err := jwt.NewWithClaims(method, claims).SignedString([]byte("test_secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
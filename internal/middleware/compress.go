@@ -0,0 +1,304 @@
+package middleware
+
+import (
+	"bufio"
+	"gin-server-template/internal/config"
+	"gin-server-template/pkg/httpcache"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// 支持的压缩编码，按优先级排列
+const (
+	encodingZstd = "zstd"
+	encodingGzip = "gzip"
+)
+
+// 默认的压缩阈值和可压缩的内容类型
+var (
+	defaultCompressMinSize      = 1024
+	defaultCompressContentTypes = []string{
+		"application/json",
+		"application/problem+json",
+		"text/plain",
+		"text/csv",
+		"text/html",
+	}
+)
+
+// 压缩器复用池，避免每个请求重新分配压缩窗口
+var (
+	gzipPool = sync.Pool{New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	}}
+	zstdPool = sync.Pool{New: func() interface{} {
+		// 浏览器要求zstd窗口不超过8MB，这里使用1MB以降低内存占用
+		encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithWindowSize(1<<20))
+		return encoder
+	}}
+)
+
+// Compress 响应压缩中间件
+//
+// 按Accept-Encoding协商zstd或gzip（同等权重时优先zstd），只压缩内容类型在允许列表中、
+// 且大小达到阈值的响应。响应体先缓冲到阈值大小再决定是否压缩，压缩时移除Content-Length；
+// 处理器调用Flush（如流式响应）时立即按内容类型决定，text/event-stream默认不压缩。
+// 内容类型可压缩的响应无论是否实际压缩都设置Vary: Accept-Encoding，
+// 避免共享缓存将未压缩的响应返回给支持压缩的客户端，或反之。
+// 需要注册在ErrorHandler之前，错误响应同样会被压缩。
+func Compress(cfg config.CompressionConfig) gin.HandlerFunc {
+	minSize := cfg.MinSize
+	if minSize <= 0 {
+		minSize = defaultCompressMinSize
+	}
+	contentTypes := cfg.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCompressContentTypes
+	}
+	allowed := make(map[string]bool, len(contentTypes))
+	for _, contentType := range contentTypes {
+		allowed[strings.ToLower(contentType)] = true
+	}
+
+	return func(c *gin.Context) {
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       negotiateEncoding(c.GetHeader("Accept-Encoding")),
+			minSize:        minSize,
+			allowed:        allowed,
+		}
+		// HEAD请求没有响应体，Range请求的偏移基于未压缩的内容；
+		// 不压缩时仍需设置Vary，首次写入即可决定，无需缓冲
+		if writer.encoding == "" || c.Request.Method == http.MethodHead || c.GetHeader("Range") != "" {
+			writer.encoding = ""
+			writer.minSize = 0
+		}
+		c.Writer = writer
+		defer func() {
+			writer.close()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding 按Accept-Encoding选择压缩编码，不支持时返回空字符串
+func negotiateEncoding(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	var best string
+	var bestQ float64
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseQuality(part)
+		if q <= 0 {
+			continue
+		}
+		switch name {
+		case encodingZstd, encodingGzip, "*":
+		default:
+			continue
+		}
+		if name == "*" {
+			name = encodingZstd
+		}
+		// 权重相同时优先zstd
+		if q > bestQ || q == bestQ && name == encodingZstd {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// parseQuality 解析形如 gzip;q=0.8 的编码及其权重，未指定权重时为1
+func parseQuality(part string) (string, float64) {
+	name, params, _ := strings.Cut(part, ";")
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok && strings.EqualFold(key, "q") {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+	}
+	return name, q
+}
+
+// compressWriter 延迟决定是否压缩的响应写入器
+//
+// 决定之前写入的数据缓冲在buf中；决定之后encoder为nil表示原样输出。encoding为空表示不压缩。
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	allowed  map[string]bool
+
+	buf     []byte
+	decided bool
+	varied  bool
+	encoder io.WriteCloser
+}
+
+// Write 缓冲响应体直到达到压缩阈值
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.minSize {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 实现gin.ResponseWriter
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written 缓冲中尚有数据时同样视为已写出，避免后续中间件重复写响应
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// WriteHeaderNow 处理器直接发送响应头（如无响应体的状态码）时同样设置Vary
+func (w *compressWriter) WriteHeaderNow() {
+	w.vary()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush 流式响应立即按内容类型决定是否压缩，并将已压缩的数据发送给客户端
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Hijack 连接被接管（如WebSocket）后不再压缩
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// decide 决定是否压缩并写出缓冲的数据，sizeOK表示响应大小已满足压缩阈值
+func (w *compressWriter) decide(sizeOK bool) error {
+	w.decided = true
+	w.vary()
+
+	if sizeOK && w.encoding != "" && w.compressible() {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		// 压缩后的内容与原内容字节不同，强ETag附加编码名，仍可用于If-Match
+		if etag := header.Get("ETag"); etag != "" {
+			header.Set("ETag", httpcache.EncodedETag(etag, w.encoding))
+		}
+		w.encoder = w.newEncoder()
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// vary 内容类型可压缩时设置Vary: Accept-Encoding，只在响应头发送之前设置一次
+func (w *compressWriter) vary() {
+	if w.varied || w.ResponseWriter.Written() {
+		return
+	}
+	w.varied = true
+
+	if w.allowedType() {
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+}
+
+// compressible 判断响应是否适合压缩，响应头已经发送时不再压缩
+func (w *compressWriter) compressible() bool {
+	if w.ResponseWriter.Written() || w.Header().Get("Content-Encoding") != "" {
+		return false
+	}
+	switch status := w.Status(); {
+	case status < http.StatusOK, status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return w.allowedType()
+}
+
+// allowedType 判断响应的内容类型是否在可压缩列表中
+func (w *compressWriter) allowedType() bool {
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return w.allowed[mediaType]
+}
+
+// newEncoder 从复用池中取出压缩器，输出到底层响应
+func (w *compressWriter) newEncoder() io.WriteCloser {
+	if w.encoding == encodingZstd {
+		encoder := zstdPool.Get().(*zstd.Encoder)
+		encoder.Reset(w.ResponseWriter)
+		return encoder
+	}
+	encoder := gzipPool.Get().(*gzip.Writer)
+	encoder.Reset(w.ResponseWriter)
+	return encoder
+}
+
+// close 请求处理完成后写出剩余数据并归还压缩器
+func (w *compressWriter) close() {
+	if !w.decided {
+		// 响应体未达到阈值，原样输出
+		w.decide(len(w.buf) >= w.minSize)
+	}
+	if w.encoder == nil {
+		return
+	}
+
+	w.encoder.Close()
+	switch encoder := w.encoder.(type) {
+	case *zstd.Encoder:
+		encoder.Reset(nil)
+		zstdPool.Put(encoder)
+	case *gzip.Writer:
+		encoder.Reset(io.Discard)
+		gzipPool.Put(encoder)
+	}
+	w.encoder = nil
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"gin-server-template/internal/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"gzip", encodingGzip},
+		{"gzip, zstd", encodingZstd},
+		{"zstd;q=0.5, gzip", encodingGzip},
+		{"gzip;q=0, zstd;q=0", ""},
+		{"br", ""},
+		{"*", encodingZstd},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.acceptEncoding); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("a", 2048)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Compress(config.CompressionConfig{MinSize: 1024}))
+	largeHandler := func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.String(http.StatusOK, large)
+	}
+	router.GET("/large", largeHandler)
+	router.HEAD("/large", largeHandler)
+	router.GET("/small", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	router.GET("/binary", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(large))
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		c.Header("Content-Type", "application/json")
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		headers  map[string]string
+		encoding string
+		vary     bool
+		etag     string
+	}{
+		{"达到阈值", http.MethodGet, "/large", map[string]string{"Accept-Encoding": "gzip"}, encodingGzip, true, `"v1-gzip"`},
+		{"未达到阈值", http.MethodGet, "/small", map[string]string{"Accept-Encoding": "gzip"}, "", true, ""},
+		{"未协商编码", http.MethodGet, "/large", nil, "", true, `"v1"`},
+		{"HEAD请求", http.MethodHead, "/large", map[string]string{"Accept-Encoding": "gzip"}, "", true, `"v1"`},
+		{"Range请求", http.MethodGet, "/large", map[string]string{"Accept-Encoding": "gzip", "Range": "bytes=0-9"}, "", true, `"v1"`},
+		{"不可压缩的内容类型", http.MethodGet, "/binary", map[string]string{"Accept-Encoding": "gzip"}, "", false, ""},
+		{"无响应体", http.MethodGet, "/empty", map[string]string{"Accept-Encoding": "gzip"}, "", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.encoding)
+			}
+			if got := w.Header().Values("Vary"); (len(got) == 1 && got[0] == "Accept-Encoding") != tt.vary {
+				t.Errorf("Vary = %q, want Accept-Encoding: %v", got, tt.vary)
+			}
+			if got := w.Header().Get("ETag"); got != tt.etag {
+				t.Errorf("ETag = %q, want %q", got, tt.etag)
+			}
+			if tt.encoding == encodingGzip {
+				reader, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(body) != large {
+					t.Error("解压后的响应体与原响应体不一致")
+				}
+			}
+		})
+	}
+}
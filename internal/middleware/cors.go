@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"errors"
+	"gin-server-template/internal/config"
+	"gin-server-template/pkg/response"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 默认允许的跨域请求方法和请求头
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", "Accept-Language", "X-Language", "X-Request-ID"}
+)
+
+// ErrCORSWildcardCredentials 允许携带凭证时不能允许所有来源
+//
+// 来源被允许时回显请求的Origin，*与allow_credentials同时配置等于允许任意网站携带用户凭证读取响应。
+var ErrCORSWildcardCredentials = errors.New("允许携带凭证时不能将allowed_origins配置为*")
+
+// CORS 跨域资源共享中间件，允许的来源列表可以在运行时更新
+type CORS struct {
+	origins          atomic.Pointer[originMatcher]
+	methods          map[string]bool
+	allowMethods     string
+	headers          map[string]bool
+	allowAnyHeader   bool
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+// NewCORS 按配置创建跨域中间件，未配置请求方法或请求头时使用默认值
+func NewCORS(cfg config.CORSConfig) (*CORS, error) {
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+
+	m := &CORS{
+		methods:          make(map[string]bool, len(methods)),
+		headers:          make(map[string]bool, len(headers)),
+		exposeHeaders:    strings.Join(cfg.ExposedHeaders, ", "),
+		allowCredentials: cfg.AllowCredentials,
+	}
+	normalized := make([]string, 0, len(methods))
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		m.methods[method] = true
+		normalized = append(normalized, method)
+	}
+	m.allowMethods = strings.Join(normalized, ", ")
+	for _, header := range headers {
+		if header == "*" {
+			m.allowAnyHeader = true
+			continue
+		}
+		m.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	m.allowHeaders = strings.Join(headers, ", ")
+	if cfg.MaxAge > 0 {
+		m.maxAge = strconv.Itoa(cfg.MaxAge)
+	}
+
+	if err := m.SetAllowedOrigins(cfg.AllowedOrigins); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SetAllowedOrigins 更新允许的来源列表，对之后的请求立即生效；列表无效时返回错误并保留原列表
+func (m *CORS) SetAllowedOrigins(origins []string) error {
+	matcher := newOriginMatcher(origins)
+	if matcher.any && m.allowCredentials {
+		return ErrCORSWildcardCredentials
+	}
+	m.origins.Store(matcher)
+	return nil
+}
+
+// Handler 返回gin中间件
+//
+// 来源被允许时回显请求的Origin而不是返回*，因此所有响应都设置Vary: Origin，避免缓存混用。
+// 预检请求（带Access-Control-Request-Method的OPTIONS请求）在此直接响应204，
+// 来源、方法或请求头不被允许时返回403。
+func (m *CORS) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		if !m.origins.Load().match(origin) {
+			if preflight {
+				c.Error(response.ErrForbidden)
+				c.Abort()
+				return
+			}
+			// 非预检请求照常处理，浏览器因缺少跨域响应头而拒绝读取响应
+			c.Next()
+			return
+		}
+
+		if preflight {
+			m.handlePreflight(c, origin)
+			return
+		}
+
+		header.Set("Access-Control-Allow-Origin", origin)
+		if m.allowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if m.exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", m.exposeHeaders)
+		}
+
+		c.Next()
+	}
+}
+
+// handlePreflight 校验预检请求声明的方法和请求头并直接响应
+func (m *CORS) handlePreflight(c *gin.Context, origin string) {
+	method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+	if !m.methods[method] {
+		c.Error(response.ErrForbidden)
+		c.Abort()
+		return
+	}
+
+	requested := c.GetHeader("Access-Control-Request-Headers")
+	for _, name := range strings.Split(requested, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !m.allowAnyHeader && !m.headers[http.CanonicalHeaderKey(name)] {
+			c.Error(response.ErrForbidden)
+			c.Abort()
+			return
+		}
+	}
+
+	header := c.Writer.Header()
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Set("Access-Control-Allow-Methods", m.allowMethods)
+	if m.allowAnyHeader {
+		// 携带凭证时浏览器不认可通配符，回显预检请求声明的请求头
+		if requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+	} else {
+		header.Set("Access-Control-Allow-Headers", m.allowHeaders)
+	}
+	if m.allowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if m.maxAge != "" {
+		header.Set("Access-Control-Max-Age", m.maxAge)
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// originMatcher 来源匹配规则
+type originMatcher struct {
+	any      bool
+	exact    map[string]bool
+	wildcard []wildcardOrigin
+}
+
+// wildcardOrigin 子域名通配规则，如https://*.example.com拆分为前缀https://和后缀.example.com
+type wildcardOrigin struct {
+	prefix string
+	suffix string
+}
+
+// newOriginMatcher 解析来源列表，来源不区分大小写
+func newOriginMatcher(origins []string) *originMatcher {
+	matcher := &originMatcher{exact: make(map[string]bool, len(origins))}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "*":
+			matcher.any = true
+		case strings.Contains(origin, "://*."):
+			i := strings.Index(origin, "*")
+			matcher.wildcard = append(matcher.wildcard, wildcardOrigin{prefix: origin[:i], suffix: origin[i+1:]})
+		case origin != "":
+			matcher.exact[origin] = true
+		}
+	}
+	return matcher
+}
+
+// match 判断来源是否被允许，通配规则只匹配子域名，不匹配主域名本身
+func (m *originMatcher) match(origin string) bool {
+	if m.any {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if m.exact[origin] {
+		return true
+	}
+	for _, w := range m.wildcard {
+		if len(origin) <= len(w.prefix)+len(w.suffix) || !strings.HasPrefix(origin, w.prefix) || !strings.HasSuffix(origin, w.suffix) {
+			continue
+		}
+		if isSubdomain(origin[len(w.prefix) : len(origin)-len(w.suffix)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubdomain 判断通配部分是否为合法的子域名标签，防止https://evil.com/.example.com之类的来源绕过
+func isSubdomain(s string) bool {
+	for _, label := range strings.Split(s, ".") {
+		if label == "" {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"errors"
+	"gin-server-template/internal/config"
+	"gin-server-template/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	matcher := newOriginMatcher([]string{"https://app.example.com", " HTTPS://*.Example.org "})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"HTTPS://APP.EXAMPLE.COM", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com.evil.com", false},
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://.example.org", false},
+		{"https://evil.com/.example.org", false},
+		{"https://evil_com.example.org", false},
+		{"http://a.example.org", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		if got := matcher.match(tt.origin); got != tt.want {
+			t.Errorf("match(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	if !newOriginMatcher([]string{"*"}).match("https://any.example") {
+		t.Error("*应匹配所有来源")
+	}
+}
+
+func TestNewCORSWildcardCredentials(t *testing.T) {
+	_, err := NewCORS(config.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	if !errors.Is(err, ErrCORSWildcardCredentials) {
+		t.Fatalf("err = %v, want ErrCORSWildcardCredentials", err)
+	}
+
+	cors, err := NewCORS(config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 运行时更新为无效列表时保留原列表
+	if err := cors.SetAllowedOrigins([]string{"*"}); !errors.Is(err, ErrCORSWildcardCredentials) {
+		t.Fatalf("err = %v, want ErrCORSWildcardCredentials", err)
+	}
+	if cors.origins.Load().match("https://evil.example") {
+		t.Error("无效的来源列表不应生效")
+	}
+	if !cors.origins.Load().match("https://app.example.com") {
+		t.Error("应保留原来源列表")
+	}
+}
+
+func TestCORSHandler(t *testing.T) {
+	cors, err := NewCORS(config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"get", "post"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(response.ErrorHandler(response.ErrorOptions{}), cors.Handler())
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", nil)
+		req.Header.Set("Origin", origin)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("允许的来源", func(t *testing.T) {
+		w := serve(http.MethodGet, "https://app.example.com", nil)
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+			t.Errorf("Access-Control-Allow-Origin = %q", got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+			t.Errorf("Access-Control-Allow-Credentials = %q", got)
+		}
+		if got := w.Header().Get("Vary"); got != "Origin" {
+			t.Errorf("Vary = %q, want Origin", got)
+		}
+	})
+
+	t.Run("不允许的来源", func(t *testing.T) {
+		w := serve(http.MethodGet, "https://evil.example", nil)
+		if w.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want empty", got)
+		}
+	})
+
+	preflight := []struct {
+		name    string
+		origin  string
+		headers map[string]string
+		status  int
+	}{
+		{"预检请求", "https://app.example.com", map[string]string{"Access-Control-Request-Method": "POST", "Access-Control-Request-Headers": "content-type"}, http.StatusNoContent},
+		{"预检不允许的来源", "https://evil.example", map[string]string{"Access-Control-Request-Method": "POST"}, http.StatusForbidden},
+		{"预检不允许的方法", "https://app.example.com", map[string]string{"Access-Control-Request-Method": "DELETE"}, http.StatusForbidden},
+		{"预检不允许的请求头", "https://app.example.com", map[string]string{"Access-Control-Request-Method": "POST", "Access-Control-Request-Headers": "X-Custom"}, http.StatusForbidden},
+	}
+	for _, tt := range preflight {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(http.MethodOptions, tt.origin, tt.headers)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.status == http.StatusNoContent {
+				if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+					t.Errorf("Access-Control-Allow-Methods = %q", got)
+				}
+				if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+					t.Errorf("Access-Control-Max-Age = %q", got)
+				}
+			}
+		})
+	}
+}
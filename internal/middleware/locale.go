@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"gin-server-template/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale 语言协商中间件
+//
+// 优先级：查询参数lang > 请求头X-Language > 请求头Accept-Language > 默认语言。
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Normalize(c.Query("lang"))
+		if locale == "" {
+			locale = i18n.Normalize(c.GetHeader("X-Language"))
+		}
+		if locale == "" {
+			locale = i18n.Negotiate(c.GetHeader("Accept-Language"))
+		}
+
+		i18n.SetLocale(c, locale)
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+
+		c.Next()
+	}
+}
@@ -1,42 +1,59 @@
 package middleware
 
 import (
-	"log"
+	"gin-server-template/pkg/logger"
+	"gin-server-template/pkg/requestid"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Logger 日志中间件，记录请求信息
+// Logger 日志中间件，为每个请求创建带请求信息的日志记录器，并在请求结束时记录访问日志
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 开始时间
 		start := time.Now()
 
+		// 请求级日志记录器，后续处理器通过logger.FromContext获取
+		l := slog.Default().With(
+			slog.String("request_id", requestid.FromContext(c.Request.Context())),
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+		)
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			l = l.With(slog.String("trace_id", sc.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), l))
+
 		// 处理请求
 		c.Next()
 
-		// 结束时间
-		end := time.Now()
-		latency := end.Sub(start)
-
-		// 请求方法和路径
-		method := c.Request.Method
-		path := c.Request.URL.Path
-
-		// 状态码
+		// 状态码决定日志级别
 		statusCode := c.Writer.Status()
-
-		// 客户端IP
-		clientIP := c.ClientIP()
-
-		// 记录日志
-		log.Printf("%s | %3d | %13v | %15s | %s",
-			method,
-			statusCode,
-			latency,
-			clientIP,
-			path,
+		level := slog.LevelInfo
+		switch {
+		case statusCode >= 500:
+			level = slog.LevelError
+		case statusCode >= 400:
+			level = slog.LevelWarn
+		}
+
+		// 认证中间件可能已向记录器追加了用户信息，因此重新从上下文获取
+		ctx := c.Request.Context()
+		logger.FromContext(ctx).LogAttrs(ctx, level, "请求完成",
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", statusCode),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("size", c.Writer.Size()),
 		)
 	}
-}
\ No newline at end of file
+}
+
+// withLogAttrs 向当前请求的日志记录器追加属性
+func withLogAttrs(c *gin.Context, args ...any) {
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(logger.WithContext(ctx, logger.FromContext(ctx).With(args...)))
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testConfig 测试使用的配置，数据库使用共享的模拟实现
+const testConfig = `
+database:
+  driver: mock
+jwt:
+  secret: test_secret
+  expire: 1
+  issuer: test
+oauth:
+  access_token_expire: 3600
+`
+
+// TestMain 中间件从工作目录下的configs/config.yaml读取配置，测试在临时目录中运行
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "middleware-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := func() int {
+		defer os.RemoveAll(dir)
+		if err := os.MkdirAll(filepath.Join(dir, "configs"), 0o755); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := os.WriteFile(filepath.Join(dir, "configs", "config.yaml"), []byte(testConfig), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := os.Chdir(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return m.Run()
+	}()
+	os.Exit(code)
+}
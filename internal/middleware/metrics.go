@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"gin-server-template/internal/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics 指标中间件，按路由模板和状态码统计请求数和处理耗时
+//
+// 使用路由模板而不是实际路径作为标签，避免路径参数导致标签基数无限增长。
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		route := c.FullPath()
+		metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"gin-server-template/internal/config"
+	"gin-server-template/pkg/ratelimit"
+	"gin-server-template/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(response.ErrorHandler(response.ErrorOptions{}))
+	policy := config.RateLimitPolicy{Requests: 2, Period: 60, Key: RateLimitKeyAPIKey, APIKeys: []string{"registered"}}
+	router.GET("/", RateLimit(ratelimit.NewMemoryStore(0), "test", policy), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(apiKey string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set(apiKeyHeader, apiKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 未登记的Key按客户端IP计数，更换Key不能绕过限流
+	for i := 0; i < 2; i++ {
+		if status := serve("random-" + strconv.Itoa(i)); status != http.StatusOK {
+			t.Fatalf("第%d个请求 status = %d, want 200", i+1, status)
+		}
+	}
+	if status := serve("random-2"); status != http.StatusTooManyRequests {
+		t.Errorf("更换未登记的Key status = %d, want 429", status)
+	}
+
+	// 已登记的Key单独计数
+	for i := 0; i < 2; i++ {
+		if status := serve("registered"); status != http.StatusOK {
+			t.Fatalf("已登记的Key第%d个请求 status = %d, want 200", i+1, status)
+		}
+	}
+	if status := serve("registered"); status != http.StatusTooManyRequests {
+		t.Errorf("已登记的Key超出限制 status = %d, want 429", status)
+	}
+}
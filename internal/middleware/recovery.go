@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"gin-server-template/internal/metrics"
+	"gin-server-template/pkg/logger"
+	"gin-server-template/pkg/response"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery panic恢复中间件
+//
+// 捕获后续处理器中的panic，记录带请求ID的调用栈并计入http_panics_total，
+// 然后通过c.Error上报内部错误，由ErrorHandler渲染为统一的错误响应，因此需要注册在ErrorHandler之后。
+// 客户端已断开连接时只记录日志，不再写响应。
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// http.ErrAbortHandler用于主动中断响应，交给net/http处理
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("%v", rec)
+			}
+
+			log := logger.FromContext(c.Request.Context())
+			if isBrokenPipe(err) {
+				log.Warn("客户端已断开连接", "error", err)
+				c.Abort()
+				return
+			}
+
+			metrics.HTTPPanicsTotal.WithLabelValues(c.FullPath()).Inc()
+			log.Error("处理请求时发生panic", "panic", err.Error(), "stack", string(debug.Stack()))
+
+			c.Error(response.ErrInternal.WithCause(fmt.Errorf("panic: %w", err)))
+			c.Abort()
+		}()
+
+		c.Next()
+	}
+}
+
+// isBrokenPipe 判断是否为写响应时客户端断开连接导致的错误
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
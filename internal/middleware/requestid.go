@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"gin-server-template/pkg/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID 请求ID中间件
+//
+// 优先使用客户端传入的X-Request-ID，缺失或格式不合法时生成新的ID；
+// 请求ID保存到gin上下文和请求上下文中，并通过响应头返回。
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Set("requestID", id)
+		c.Request = c.Request.WithContext(requestid.WithContext(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+
+		c.Next()
+	}
+}
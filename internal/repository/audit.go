@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"gin-server-template/internal/config"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/repository/mongodb"
+	"gin-server-template/internal/repository/mysql"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AuditRepository 审计事件数据访问接口
+type AuditRepository interface {
+	// Create 保存审计事件
+	Create(ctx context.Context, event *entity.AuditEvent) error
+
+	// List 按条件分页查询审计事件，按时间倒序排列，同时返回符合条件的总数
+	List(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEvent, int64, error)
+}
+
+// NewAuditRepository 创建审计事件仓库实例
+func NewAuditRepository() AuditRepository {
+	// 获取当前配置
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err == nil {
+		switch cfg.Database.Driver {
+		case "mysql":
+			return mysql.NewAuditRepository()
+		case "mongodb":
+			return mongodb.NewAuditRepository()
+		}
+	}
+
+	// 默认返回共享的模拟实现
+	return mockAuditRepo
+}
+
+// mockAuditRepo 共享的模拟实现实例
+var mockAuditRepo = &mockAuditRepository{nextID: 1}
+
+// 模拟实现，用于开发和测试
+type mockAuditRepository struct {
+	mu     sync.Mutex
+	events []*entity.AuditEvent
+	nextID uint
+}
+
+func (r *mockAuditRepository) Create(ctx context.Context, event *entity.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.ID = r.nextID
+	event.CreatedAt = time.Now()
+	r.nextID++
+
+	stored := *event
+	r.events = append(r.events, &stored)
+	return nil
+}
+
+func (r *mockAuditRepository) List(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEvent, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []*entity.AuditEvent
+	for _, event := range r.events {
+		if matchAuditFilter(event, filter) {
+			copied := *event
+			matched = append(matched, &copied)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].ID > matched[j].ID
+	})
+
+	total := int64(len(matched))
+	start := filter.Offset()
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + filter.PageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[start:end], total, nil
+}
+
+// matchAuditFilter 检查审计事件是否符合查询条件
+func matchAuditFilter(event *entity.AuditEvent, filter entity.AuditFilter) bool {
+	switch {
+	case filter.Action != "" && event.Action != filter.Action:
+		return false
+	case filter.ActorID != "" && event.ActorID != filter.ActorID:
+		return false
+	case filter.TargetID != "" && event.TargetID != filter.TargetID:
+		return false
+	case filter.RequestID != "" && event.RequestID != filter.RequestID:
+		return false
+	case filter.Success != nil && event.Success != *filter.Success:
+		return false
+	case !filter.From.IsZero() && event.CreatedAt.Before(filter.From):
+		return false
+	case !filter.To.IsZero() && !event.CreatedAt.Before(filter.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"gin-server-template/internal/config"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/repository/mongodb"
+	"gin-server-template/internal/repository/mysql"
+	"sync"
+)
+
+// EmailChangeRepository 邮箱修改请求数据访问接口
+//
+// 记录不存在时返回entity.ErrNotFound。
+type EmailChangeRepository interface {
+	// Create 保存邮箱修改请求
+	Create(ctx context.Context, change *entity.EmailChange) error
+
+	// GetByTokenHash 根据确认令牌的摘要获取邮箱修改请求
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error)
+
+	// DeleteByUserID 删除用户所有未确认的邮箱修改请求，使之前发出的确认令牌失效
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+// NewEmailChangeRepository 创建邮箱修改请求仓库实例
+func NewEmailChangeRepository() EmailChangeRepository {
+	// 获取当前配置
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err == nil {
+		switch cfg.Database.Driver {
+		case "mysql":
+			return mysql.NewEmailChangeRepository()
+		case "mongodb":
+			return mongodb.NewEmailChangeRepository()
+		}
+	}
+
+	// 默认返回共享的模拟实现
+	return mockEmailChangeRepo
+}
+
+// mockEmailChangeRepo 共享的模拟实现实例
+var mockEmailChangeRepo = newMockEmailChangeRepository()
+
+// 模拟实现，用于开发和测试
+type mockEmailChangeRepository struct {
+	mu      sync.Mutex
+	changes map[string]*entity.EmailChange
+	nextID  uint
+}
+
+func newMockEmailChangeRepository() *mockEmailChangeRepository {
+	return &mockEmailChangeRepository{
+		changes: make(map[string]*entity.EmailChange),
+		nextID:  1,
+	}
+}
+
+func (r *mockEmailChangeRepository) Create(ctx context.Context, change *entity.EmailChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.changes[change.TokenHash]; exists {
+		return entity.ErrDuplicate
+	}
+	change.ID = r.nextID
+	r.nextID++
+	r.changes[change.TokenHash] = change
+	return nil
+}
+
+func (r *mockEmailChangeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	change, exists := r.changes[tokenHash]
+	if !exists {
+		return nil, entity.ErrNotFound
+	}
+	copied := *change
+	return &copied, nil
+}
+
+func (r *mockEmailChangeRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for tokenHash, change := range r.changes {
+		if change.UserID == userID {
+			delete(r.changes, tokenHash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package mongodb
+
+import (
+	"context"
+	"gin-server-template/internal/database"
+	"gin-server-template/internal/entity"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditRepository MongoDB实现的审计事件仓库
+type AuditRepository struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+// NewAuditRepository 创建MongoDB审计事件仓库实例
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{
+		client:     database.GetMongoDB(),
+		database:   database.GetMongoDBName(),
+		collection: "audit_events",
+	}
+}
+
+// getCollection 获取审计事件集合
+func (r *AuditRepository) getCollection() *mongo.Collection {
+	return r.client.Database(r.database).Collection(r.collection)
+}
+
+// Create 保存审计事件
+func (r *AuditRepository) Create(ctx context.Context, event *entity.AuditEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	event.CreatedAt = time.Now()
+
+	_, err := r.getCollection().InsertOne(ctx, event, insertOneOptions(ctx))
+	return translateError(err)
+}
+
+// List 按条件分页查询审计事件
+func (r *AuditRepository) List(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEvent, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.ActorID != "" {
+		query["actorid"] = filter.ActorID
+	}
+	if filter.TargetID != "" {
+		query["targetid"] = filter.TargetID
+	}
+	if filter.RequestID != "" {
+		query["requestid"] = filter.RequestID
+	}
+	if filter.Success != nil {
+		query["success"] = *filter.Success
+	}
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		query["createdat"] = createdAt
+	}
+
+	total, err := r.getCollection().CountDocuments(ctx, query, countOptions(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "createdat", Value: -1}}).
+		SetSkip(int64(filter.Offset())).
+		SetLimit(int64(filter.PageSize))
+	if c := comment(ctx); c != "" {
+		findOpts.SetComment(c)
+	}
+
+	cursor, err := r.getCollection().Find(ctx, query, findOpts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []*entity.AuditEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
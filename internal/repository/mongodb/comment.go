@@ -0,0 +1,70 @@
+package mongodb
+
+import (
+	"context"
+	"gin-server-template/pkg/requestid"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// comment 根据上下文中的请求ID生成操作注释，注释会出现在MongoDB的慢查询日志和profiler中
+func comment(ctx context.Context) string {
+	if id := requestid.FromContext(ctx); id != "" {
+		return "request_id=" + id
+	}
+	return ""
+}
+
+// findOneOptions 带请求ID注释的FindOne选项
+func findOneOptions(ctx context.Context) *options.FindOneOptions {
+	opts := options.FindOne()
+	if c := comment(ctx); c != "" {
+		opts.SetComment(c)
+	}
+	return opts
+}
+
+// countOptions 带请求ID注释的CountDocuments选项
+func countOptions(ctx context.Context) *options.CountOptions {
+	opts := options.Count()
+	if c := comment(ctx); c != "" {
+		opts.SetComment(c)
+	}
+	return opts
+}
+
+// insertOneOptions 带请求ID注释的InsertOne选项
+func insertOneOptions(ctx context.Context) *options.InsertOneOptions {
+	opts := options.InsertOne()
+	if c := comment(ctx); c != "" {
+		opts.SetComment(c)
+	}
+	return opts
+}
+
+// updateOptions 带请求ID注释的UpdateOne选项
+func updateOptions(ctx context.Context) *options.UpdateOptions {
+	opts := options.Update()
+	if c := comment(ctx); c != "" {
+		opts.SetComment(c)
+	}
+	return opts
+}
+
+// deleteOptions 带请求ID注释的DeleteOne选项
+func deleteOptions(ctx context.Context) *options.DeleteOptions {
+	opts := options.Delete()
+	if c := comment(ctx); c != "" {
+		opts.SetComment(c)
+	}
+	return opts
+}
+
+// findOneAndDeleteOptions 带请求ID注释的FindOneAndDelete选项
+func findOneAndDeleteOptions(ctx context.Context) *options.FindOneAndDeleteOptions {
+	opts := options.FindOneAndDelete()
+	if c := comment(ctx); c != "" {
+		opts.SetComment(c)
+	}
+	return opts
+}
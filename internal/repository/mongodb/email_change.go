@@ -0,0 +1,70 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/database"
+	"gin-server-template/internal/entity"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// EmailChangeRepository MongoDB实现的邮箱修改请求仓库
+type EmailChangeRepository struct {
+	client     *mongo.Client
+	database   string
+	collection string
+}
+
+// NewEmailChangeRepository 创建MongoDB邮箱修改请求仓库实例
+func NewEmailChangeRepository() *EmailChangeRepository {
+	return &EmailChangeRepository{
+		client:     database.GetMongoDB(),
+		database:   database.GetMongoDBName(),
+		collection: "email_changes",
+	}
+}
+
+// getCollection 获取邮箱修改请求集合
+func (r *EmailChangeRepository) getCollection() *mongo.Collection {
+	return r.client.Database(r.database).Collection(r.collection)
+}
+
+// Create 保存邮箱修改请求
+func (r *EmailChangeRepository) Create(ctx context.Context, change *entity.EmailChange) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	change.CreatedAt = time.Now()
+
+	_, err := r.getCollection().InsertOne(ctx, change, insertOneOptions(ctx))
+	return translateError(err)
+}
+
+// GetByTokenHash 根据确认令牌的摘要获取邮箱修改请求
+func (r *EmailChangeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var change entity.EmailChange
+	err := r.getCollection().FindOne(ctx, bson.M{"tokenhash": tokenHash}, findOneOptions(ctx)).Decode(&change)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// DeleteByUserID 删除用户所有未确认的邮箱修改请求
+func (r *EmailChangeRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.getCollection().DeleteMany(ctx, bson.M{"userid": userID}, deleteOptions(ctx))
+	return err
+}
@@ -0,0 +1,22 @@
+package mongodb
+
+import (
+	"errors"
+	"gin-server-template/internal/entity"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// translateError 将MongoDB错误转换为领域错误
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return entity.ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return entity.ErrDuplicate
+	default:
+		return err
+	}
+}
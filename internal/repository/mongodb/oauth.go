@@ -0,0 +1,142 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/database"
+	"gin-server-template/internal/entity"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OAuthRepository MongoDB实现的OAuth2仓库
+type OAuthRepository struct {
+	client   *mongo.Client
+	database string
+}
+
+// NewOAuthRepository 创建MongoDB OAuth2仓库实例
+func NewOAuthRepository() *OAuthRepository {
+	return &OAuthRepository{
+		client:   database.GetMongoDB(),
+		database: database.GetMongoDBName(),
+	}
+}
+
+// getCollection 获取指定集合
+func (r *OAuthRepository) getCollection(name string) *mongo.Collection {
+	return r.client.Database(r.database).Collection(name)
+}
+
+// CreateClient 创建客户端
+func (r *OAuthRepository) CreateClient(ctx context.Context, client *entity.OAuthClient) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// 设置创建时间和更新时间
+	now := time.Now()
+	client.CreatedAt = now
+	client.UpdatedAt = now
+
+	_, err := r.getCollection("oauth_clients").InsertOne(ctx, client, insertOneOptions(ctx))
+	return translateError(err)
+}
+
+// GetClientByClientID 根据客户端标识获取客户端
+func (r *OAuthRepository) GetClientByClientID(ctx context.Context, clientID string) (*entity.OAuthClient, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var client entity.OAuthClient
+	err := r.getCollection("oauth_clients").FindOne(ctx, bson.M{"clientid": clientID}, findOneOptions(ctx)).Decode(&client)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// CreateAuthorizationCode 保存授权码
+func (r *OAuthRepository) CreateAuthorizationCode(ctx context.Context, code *entity.OAuthAuthorizationCode) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	code.CreatedAt = time.Now()
+
+	_, err := r.getCollection("oauth_authorization_codes").InsertOne(ctx, code, insertOneOptions(ctx))
+	return translateError(err)
+}
+
+// ConsumeAuthorizationCode 取出并删除授权码
+func (r *OAuthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*entity.OAuthAuthorizationCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// FindOneAndDelete是原子操作，保证授权码只能被兑换一次
+	var code entity.OAuthAuthorizationCode
+	err := r.getCollection("oauth_authorization_codes").
+		FindOneAndDelete(ctx, bson.M{"codehash": codeHash}, findOneAndDeleteOptions(ctx)).Decode(&code)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// RevokeToken 记录已撤销的令牌
+func (r *OAuthRepository) RevokeToken(ctx context.Context, token *entity.RevokedToken) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	token.CreatedAt = time.Now()
+
+	// 以jti为键进行upsert，重复撤销时不会产生重复记录
+	_, err := r.getCollection("revoked_tokens").UpdateOne(
+		ctx,
+		bson.M{"jti": token.JTI},
+		bson.M{"$setOnInsert": token},
+		updateOptions(ctx).SetUpsert(true),
+	)
+	return err
+}
+
+// IsTokenRevoked 检查令牌是否已被撤销
+func (r *OAuthRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// TTL索引的清理存在延迟，查询时同样排除已过期的记录
+	filter := bson.M{"jti": jti, "expiresat": bson.M{"$gt": time.Now()}}
+	count, err := r.getCollection("revoked_tokens").CountDocuments(ctx, filter, countOptions(ctx))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// DeleteExpired 删除过期的授权码和撤销记录
+//
+// 两个集合都建有expiresat上的TTL索引，由MongoDB在后台自动清理，无需定期调用。
+func (r *OAuthRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var deleted int64
+	for _, collection := range []string{"oauth_authorization_codes", "revoked_tokens"} {
+		result, err := r.getCollection(collection).DeleteMany(ctx, bson.M{"expiresat": bson.M{"$lt": before}}, deleteOptions(ctx))
+		if err != nil {
+			return deleted, err
+		}
+		deleted += result.DeletedCount
+	}
+	return deleted, nil
+}
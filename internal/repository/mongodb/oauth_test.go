@@ -0,0 +1,40 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestOAuthRepositoryIsTokenRevoked(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("只查询未过期的撤销记录", func(mt *mtest.T) {
+		repo := &OAuthRepository{client: mt.Client, database: mt.DB.Name()}
+
+		// CountDocuments通过聚合实现
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".revoked_tokens", mtest.FirstBatch, bson.D{{Key: "n", Value: int32(1)}}))
+
+		revoked, err := repo.IsTokenRevoked(context.Background(), "abc")
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if !revoked {
+			mt.Error("revoked = false, want true")
+		}
+
+		started := mt.GetStartedEvent()
+		if started == nil || started.CommandName != "aggregate" {
+			mt.Fatalf("命令应为aggregate: %v", started)
+		}
+		match := started.Command.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match").Document()
+		if got := match.Lookup("jti").StringValue(); got != "abc" {
+			mt.Errorf("jti = %q, want abc", got)
+		}
+		if _, err := match.LookupErr("expiresat", "$gt"); err != nil {
+			mt.Errorf("条件中缺少expiresat: %v", match)
+		}
+	})
+}
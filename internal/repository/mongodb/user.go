@@ -3,8 +3,10 @@ package mongodb
 import (
 	"context"
 	"errors"
+	"fmt"
 	"gin-server-template/internal/database"
 	"gin-server-template/internal/entity"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -35,19 +37,20 @@ func (r *UserRepository) getCollection() *mongo.Collection {
 }
 
 // Create 创建用户
-func (r *UserRepository) Create(user *entity.User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	// 设置创建时间和更新时间
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	user.Version = 1
 
 	// 插入文档
-	result, err := r.getCollection().InsertOne(ctx, user)
+	result, err := r.getCollection().InsertOne(ctx, user, insertOneOptions(ctx))
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	// 获取插入的ID
@@ -61,15 +64,15 @@ func (r *UserRepository) Create(user *entity.User) error {
 }
 
 // GetByID 根据ID获取用户
-func (r *UserRepository) GetByID(id uint) (*entity.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	var user entity.User
-	err := r.getCollection().FindOne(ctx, bson.M{"id": id}).Decode(&user)
+	err := r.getCollection().FindOne(ctx, bson.M{"id": id}, findOneOptions(ctx)).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil
+			return nil, entity.ErrNotFound
 		}
 		return nil, err
 	}
@@ -78,15 +81,15 @@ func (r *UserRepository) GetByID(id uint) (*entity.User, error) {
 }
 
 // GetByUsername 根据用户名获取用户
-func (r *UserRepository) GetByUsername(username string) (*entity.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	var user entity.User
-	err := r.getCollection().FindOne(ctx, bson.M{"username": username}).Decode(&user)
+	err := r.getCollection().FindOne(ctx, bson.M{"username": username}, findOneOptions(ctx)).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil
+			return nil, entity.ErrNotFound
 		}
 		return nil, err
 	}
@@ -95,11 +98,11 @@ func (r *UserRepository) GetByUsername(username string) (*entity.User, error) {
 }
 
 // ExistsByUsername 检查用户名是否存在
-func (r *UserRepository) ExistsByUsername(username string) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	count, err := r.getCollection().CountDocuments(ctx, bson.M{"username": username})
+	count, err := r.getCollection().CountDocuments(ctx, bson.M{"username": username}, countOptions(ctx))
 	if err != nil {
 		return false, err
 	}
@@ -108,11 +111,11 @@ func (r *UserRepository) ExistsByUsername(username string) (bool, error) {
 }
 
 // ExistsByEmail 检查邮箱是否存在
-func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	count, err := r.getCollection().CountDocuments(ctx, bson.M{"email": email})
+	count, err := r.getCollection().CountDocuments(ctx, bson.M{"email": email}, countOptions(ctx))
 	if err != nil {
 		return false, err
 	}
@@ -120,29 +123,102 @@ func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
 	return count > 0, nil
 }
 
-// Update 更新用户信息
-func (r *UserRepository) Update(user *entity.User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// Update 按版本号条件更新用户信息
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	return r.updateVersioned(ctx, user, mutableFields(user))
+}
+
+// UpdateFields 按版本号条件只更新指定的字段
+func (r *UserRepository) UpdateFields(ctx context.Context, user *entity.User, fields ...string) error {
+	values := mutableFields(user)
+	set := bson.M{}
+	for _, field := range fields {
+		// MySQL列名去掉下划线即为驱动默认的字段名，如pending_email对应pendingemail
+		name := strings.ReplaceAll(field, "_", "")
+		value, ok := values[name]
+		if !ok {
+			return fmt.Errorf("不支持更新的字段: %s", field)
+		}
+		set[name] = value
+	}
+	return r.updateVersioned(ctx, user, set)
+}
+
+// mutableFields 返回允许更新的字段，不包括id、用户名和创建时间；
+// 字段名为驱动默认的小写形式
+func mutableFields(user *entity.User) bson.M {
+	return bson.M{
+		"email":        user.Email,
+		"pendingemail": user.PendingEmail,
+		"password":     user.Password,
+		"nickname":     user.Nickname,
+		"avatar":       user.Avatar,
+		"avatarkeys":   user.AvatarKeys,
+		"status":       user.Status,
+	}
+}
+
+// updateVersioned 以读取时的版本号为条件写入set中的字段，同时更新修改时间并将版本号加1
+func (r *UserRepository) updateVersioned(ctx context.Context, user *entity.User, set bson.M) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	// 更新时间
-	user.UpdatedAt = time.Now()
+	updatedAt := time.Now()
+	version := user.Version
+	set["updatedat"] = updatedAt
+	set["version"] = version + 1
 
-	// 更新文档
-	_, err := r.getCollection().UpdateOne(
+	// 文档在读取之后被其他请求修改过时不会匹配
+	result, err := r.getCollection().UpdateOne(
 		ctx,
-		bson.M{"id": user.ID},
-		bson.M{"$set": user},
+		bson.M{"id": user.ID, "version": versionFilter(version)},
+		bson.M{"$set": set},
+		updateOptions(ctx),
 	)
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return r.notFoundOrConflict(ctx, user.ID)
+	}
 
-	return err
+	user.UpdatedAt = updatedAt
+	user.Version = version + 1
+	return nil
+}
+
+// notFoundOrConflict 条件更新未匹配时区分文档不存在和版本冲突
+func (r *UserRepository) notFoundOrConflict(ctx context.Context, id uint) error {
+	count, err := r.getCollection().CountDocuments(ctx, bson.M{"id": id}, countOptions(ctx))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return entity.ErrNotFound
+	}
+	return entity.ErrConflict
+}
+
+// versionFilter 匹配指定的版本号，版本号为0时同时匹配引入版本号之前写入、没有该字段的文档
+func versionFilter(version uint) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
 }
 
 // Delete 删除用户
-func (r *UserRepository) Delete(id uint) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	_, err := r.getCollection().DeleteOne(ctx, bson.M{"id": id})
-	return err
+	result, err := r.getCollection().DeleteOne(ctx, bson.M{"id": id}, deleteOptions(ctx))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
 }
@@ -0,0 +1,64 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/entity"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestUserRepositoryUpdateVersion(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name        string
+		matched     int32
+		count       int32
+		err         error
+		wantVersion uint
+	}{
+		{"版本号一致", 1, 0, nil, 4},
+		{"版本号已被修改", 0, 1, entity.ErrConflict, 3},
+		{"用户不存在", 0, 0, entity.ErrNotFound, 3},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			repo := &UserRepository{client: mt.Client, database: mt.DB.Name(), collection: mt.Coll.Name()}
+
+			responses := []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "n", Value: tt.matched}, bson.E{Key: "nModified", Value: tt.matched})}
+			if tt.matched == 0 {
+				// CountDocuments通过聚合实现
+				responses = append(responses, mtest.CreateCursorResponse(0, mt.DB.Name()+"."+mt.Coll.Name(), mtest.FirstBatch, bson.D{{Key: "n", Value: tt.count}}))
+			}
+			mt.AddMockResponses(responses...)
+
+			user := &entity.User{ID: 7, Username: "alice", Nickname: "Alice", Version: 3}
+			err := repo.UpdateFields(context.Background(), user, "nickname")
+			if !errors.Is(err, tt.err) {
+				mt.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if user.Version != tt.wantVersion {
+				mt.Errorf("Version = %d, want %d", user.Version, tt.wantVersion)
+			}
+
+			// 更新以读取时的版本号为条件，并写入加1后的版本号
+			started := mt.GetStartedEvent()
+			if started == nil || started.CommandName != "update" {
+				mt.Fatalf("第一个命令应为update: %v", started)
+			}
+			update := started.Command.Lookup("updates").Array().Index(0).Value().Document()
+			if got := update.Lookup("q", "version").Int64(); got != 3 {
+				mt.Errorf("条件中的版本号 = %d, want 3", got)
+			}
+			if got := update.Lookup("u", "$set", "version").Int64(); got != 4 {
+				mt.Errorf("写入的版本号 = %d, want 4", got)
+			}
+			if got := update.Lookup("u", "$set", "nickname").StringValue(); got != "Alice" {
+				mt.Errorf("写入的昵称 = %q, want Alice", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"context"
+	"gin-server-template/internal/database"
+	"gin-server-template/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository MySQL实现的审计事件仓库
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository 创建MySQL审计事件仓库实例
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 保存审计事件
+func (r *AuditRepository) Create(ctx context.Context, event *entity.AuditEvent) error {
+	return translateError(r.db.WithContext(ctx).Create(event).Error)
+}
+
+// List 按条件分页查询审计事件
+func (r *AuditRepository) List(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEvent, int64, error) {
+	query := r.db.WithContext(ctx).Model(&entity.AuditEvent{})
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []*entity.AuditEvent
+	err := query.Order("created_at DESC, id DESC").
+		Offset(filter.Offset()).
+		Limit(filter.PageSize).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
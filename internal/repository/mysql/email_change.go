@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/database"
+	"gin-server-template/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// EmailChangeRepository MySQL实现的邮箱修改请求仓库
+type EmailChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailChangeRepository 创建MySQL邮箱修改请求仓库实例
+func NewEmailChangeRepository() *EmailChangeRepository {
+	return &EmailChangeRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 保存邮箱修改请求
+func (r *EmailChangeRepository) Create(ctx context.Context, change *entity.EmailChange) error {
+	return translateError(r.db.WithContext(ctx).Create(change).Error)
+}
+
+// GetByTokenHash 根据确认令牌的摘要获取邮箱修改请求
+func (r *EmailChangeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error) {
+	var change entity.EmailChange
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&change).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, err
+	}
+	return &change, nil
+}
+
+// DeleteByUserID 删除用户所有未确认的邮箱修改请求
+func (r *EmailChangeRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.EmailChange{}).Error
+}
@@ -0,0 +1,22 @@
+package mysql
+
+import (
+	"errors"
+	"gin-server-template/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// translateError 将GORM错误转换为领域错误
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return entity.ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return entity.ErrDuplicate
+	default:
+		return err
+	}
+}
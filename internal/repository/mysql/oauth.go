@@ -0,0 +1,98 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/database"
+	"gin-server-template/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OAuthRepository MySQL实现的OAuth2仓库
+type OAuthRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthRepository 创建MySQL OAuth2仓库实例
+func NewOAuthRepository() *OAuthRepository {
+	return &OAuthRepository{
+		db: database.GetDB(),
+	}
+}
+
+// CreateClient 创建客户端
+func (r *OAuthRepository) CreateClient(ctx context.Context, client *entity.OAuthClient) error {
+	return translateError(r.db.WithContext(ctx).Create(client).Error)
+}
+
+// GetClientByClientID 根据客户端标识获取客户端
+func (r *OAuthRepository) GetClientByClientID(ctx context.Context, clientID string) (*entity.OAuthClient, error) {
+	var client entity.OAuthClient
+	result := r.db.WithContext(ctx).Where("client_id = ?", clientID).First(&client)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, result.Error
+	}
+	return &client, nil
+}
+
+// CreateAuthorizationCode 保存授权码
+func (r *OAuthRepository) CreateAuthorizationCode(ctx context.Context, code *entity.OAuthAuthorizationCode) error {
+	return translateError(r.db.WithContext(ctx).Create(code).Error)
+}
+
+// ConsumeAuthorizationCode 取出并删除授权码
+func (r *OAuthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*entity.OAuthAuthorizationCode, error) {
+	var code entity.OAuthAuthorizationCode
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 加行锁，防止同一授权码被并发兑换
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code_hash = ?", codeHash).First(&code).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&code).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// RevokeToken 记录已撤销的令牌
+func (r *OAuthRepository) RevokeToken(ctx context.Context, token *entity.RevokedToken) error {
+	// 重复撤销同一令牌时忽略
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// IsTokenRevoked 检查令牌是否已被撤销
+func (r *OAuthRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	// 令牌过期后无法再通过校验，只需检查未过期的撤销记录
+	err := r.db.WithContext(ctx).Model(&entity.RevokedToken{}).
+		Where("jti = ? AND expires_at > ?", jti, time.Now()).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteExpired 删除过期的授权码和撤销记录，未兑换的授权码和撤销记录过期后不再有用
+func (r *OAuthRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	for _, model := range []any{&entity.OAuthAuthorizationCode{}, &entity.RevokedToken{}} {
+		result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(model)
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+	}
+	return deleted, nil
+}
@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOAuthRepositoryIsTokenRevoked(t *testing.T) {
+	repo, mock := newTestOAuthRepository(t)
+
+	// 只统计未过期的撤销记录
+	mock.ExpectQuery("^SELECT count\\(\\*\\) FROM `revoked_tokens` WHERE jti = \\? AND expires_at > \\?$").
+		WithArgs("abc", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	revoked, err := repo.IsTokenRevoked(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !revoked {
+		t.Error("revoked = false, want true")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOAuthRepositoryDeleteExpired(t *testing.T) {
+	repo, mock := newTestOAuthRepository(t)
+	before := time.Now()
+
+	mock.ExpectExec("^DELETE FROM `oauth_authorization_codes` WHERE expires_at < \\?$").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("^DELETE FROM `revoked_tokens` WHERE expires_at < \\?$").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteExpired(context.Background(), before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 5 {
+		t.Errorf("deleted = %d, want 5", deleted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// newTestOAuthRepository 创建连接到sqlmock的OAuth2仓库
+func newTestOAuthRepository(t *testing.T) (*OAuthRepository, sqlmock.Sqlmock) {
+	db, mock := newTestDB(t)
+	return &OAuthRepository{db: db}, mock
+}
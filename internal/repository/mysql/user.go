@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"errors"
 	"gin-server-template/internal/database"
 	"gin-server-template/internal/entity"
@@ -21,17 +22,18 @@ func NewUserRepository() *UserRepository {
 }
 
 // Create 创建用户
-func (r *UserRepository) Create(user *entity.User) error {
-	return r.db.Create(user).Error
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
+	user.Version = 1
+	return translateError(r.db.WithContext(ctx).Create(user).Error)
 }
 
 // GetByID 根据ID获取用户
-func (r *UserRepository) GetByID(id uint) (*entity.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
 	var user entity.User
-	result := r.db.First(&user, id)
+	result := r.db.WithContext(ctx).First(&user, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
+			return nil, entity.ErrNotFound
 		}
 		return nil, result.Error
 	}
@@ -39,12 +41,12 @@ func (r *UserRepository) GetByID(id uint) (*entity.User, error) {
 }
 
 // GetByUsername 根据用户名获取用户
-func (r *UserRepository) GetByUsername(username string) (*entity.User, error) {
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var user entity.User
-	result := r.db.Where("username = ?", username).First(&user)
+	result := r.db.WithContext(ctx).Where("username = ?", username).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
+			return nil, entity.ErrNotFound
 		}
 		return nil, result.Error
 	}
@@ -52,9 +54,9 @@ func (r *UserRepository) GetByUsername(username string) (*entity.User, error) {
 }
 
 // ExistsByUsername 检查用户名是否存在
-func (r *UserRepository) ExistsByUsername(username string) (bool, error) {
+func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	var count int64
-	err := r.db.Model(&entity.User{}).Where("username = ?", username).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&entity.User{}).Where("username = ?", username).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
@@ -62,21 +64,68 @@ func (r *UserRepository) ExistsByUsername(username string) (bool, error) {
 }
 
 // ExistsByEmail 检查邮箱是否存在
-func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	var count int64
-	err := r.db.Model(&entity.User{}).Where("email = ?", email).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&entity.User{}).Where("email = ?", email).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-// Update 更新用户信息
-func (r *UserRepository) Update(user *entity.User) error {
-	return r.db.Save(user).Error
+// Update 按版本号条件更新用户信息
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	return r.updateVersioned(ctx, user, func(db *gorm.DB) *gorm.DB {
+		return db.Select("*").Omit("created_at")
+	})
+}
+
+// UpdateFields 按版本号条件只更新指定的列，零值同样会被写入
+func (r *UserRepository) UpdateFields(ctx context.Context, user *entity.User, fields ...string) error {
+	columns := append([]string{"version", "updated_at"}, fields...)
+	return r.updateVersioned(ctx, user, func(db *gorm.DB) *gorm.DB {
+		return db.Select(columns)
+	})
+}
+
+// updateVersioned 以读取时的版本号为条件执行更新，selectColumns指定要写入的列
+//
+// 不使用Save，避免记录不存在时被重新插入；记录在读取之后被其他请求修改过时不会更新任何行。
+func (r *UserRepository) updateVersioned(ctx context.Context, user *entity.User, selectColumns func(*gorm.DB) *gorm.DB) error {
+	version := user.Version
+	user.Version++
+	result := selectColumns(r.db.WithContext(ctx).Model(user).Where("version = ?", version)).Updates(user)
+	if result.Error != nil {
+		user.Version = version
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		user.Version = version
+		return r.notFoundOrConflict(ctx, user.ID)
+	}
+	return nil
+}
+
+// notFoundOrConflict 条件更新未命中时区分记录不存在和版本冲突
+func (r *UserRepository) notFoundOrConflict(ctx context.Context, id uint) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return entity.ErrNotFound
+	}
+	return entity.ErrConflict
 }
 
 // Delete 删除用户
-func (r *UserRepository) Delete(id uint) error {
-	return r.db.Delete(&entity.User{}, id).Error
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&entity.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
 }
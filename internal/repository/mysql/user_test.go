@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/entity"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestUserRepositoryUpdateVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        int64
+		count       int64
+		err         error
+		wantVersion uint
+	}{
+		{"版本号一致", 1, 0, nil, 4},
+		{"版本号已被修改", 0, 1, entity.ErrConflict, 3},
+		{"用户不存在", 0, 0, entity.ErrNotFound, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newTestUserRepository(t)
+
+			// 条件更新以读取时的版本号为条件，并写入加1后的版本号
+			mock.ExpectExec("^UPDATE `users` SET `nickname`=\\?,`version`=\\?,`updated_at`=\\? WHERE version = \\? AND `id` = \\?$").
+				WithArgs("Alice", 4, sqlmock.AnyArg(), 3, 7).
+				WillReturnResult(sqlmock.NewResult(0, tt.rows))
+			if tt.rows == 0 {
+				mock.ExpectQuery("^SELECT count\\(\\*\\) FROM `users` WHERE id = \\?").
+					WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.count))
+			}
+
+			user := &entity.User{ID: 7, Username: "alice", Nickname: "Alice", Version: 3}
+			err := repo.UpdateFields(context.Background(), user, "nickname")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if user.Version != tt.wantVersion {
+				t.Errorf("Version = %d, want %d", user.Version, tt.wantVersion)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// newTestUserRepository 创建连接到sqlmock的用户仓库
+func newTestUserRepository(t *testing.T) (*UserRepository, sqlmock.Sqlmock) {
+	db, mock := newTestDB(t)
+	return &UserRepository{db: db}, mock
+}
+
+// newTestDB 创建连接到sqlmock的GORM实例
+func newTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{SkipDefaultTransaction: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"gin-server-template/internal/config"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/repository/mongodb"
+	"gin-server-template/internal/repository/mysql"
+	"sync"
+	"time"
+)
+
+// OAuthRepository OAuth2数据访问接口
+//
+// 记录不存在时返回entity.ErrNotFound，违反唯一约束时返回entity.ErrDuplicate。
+type OAuthRepository interface {
+	// CreateClient 创建客户端
+	CreateClient(ctx context.Context, client *entity.OAuthClient) error
+
+	// GetClientByClientID 根据客户端标识获取客户端
+	GetClientByClientID(ctx context.Context, clientID string) (*entity.OAuthClient, error)
+
+	// CreateAuthorizationCode 保存授权码
+	CreateAuthorizationCode(ctx context.Context, code *entity.OAuthAuthorizationCode) error
+
+	// ConsumeAuthorizationCode 取出并删除授权码，保证授权码只能使用一次
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*entity.OAuthAuthorizationCode, error)
+
+	// RevokeToken 记录已撤销的令牌
+	RevokeToken(ctx context.Context, token *entity.RevokedToken) error
+
+	// IsTokenRevoked 检查令牌是否已被撤销，令牌过期后撤销记录不再生效
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+
+	// DeleteExpired 删除在指定时间之前过期的授权码和撤销记录，返回删除的记录数
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NewOAuthRepository 创建OAuth2仓库实例
+func NewOAuthRepository() OAuthRepository {
+	// 获取当前配置
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err == nil {
+		switch cfg.Database.Driver {
+		case "mysql":
+			return mysql.NewOAuthRepository()
+		case "mongodb":
+			return mongodb.NewOAuthRepository()
+		}
+	}
+
+	// 默认返回共享的模拟实现，保证各服务看到同一份数据
+	return mockOAuthRepo
+}
+
+// mockOAuthRepo 共享的模拟实现实例
+var mockOAuthRepo = newMockOAuthRepository()
+
+// 模拟实现，用于开发和测试
+type mockOAuthRepository struct {
+	mu      sync.Mutex
+	clients map[string]*entity.OAuthClient
+	codes   map[string]*entity.OAuthAuthorizationCode
+	revoked map[string]*entity.RevokedToken
+	nextID  uint
+}
+
+func newMockOAuthRepository() *mockOAuthRepository {
+	return &mockOAuthRepository{
+		clients: make(map[string]*entity.OAuthClient),
+		codes:   make(map[string]*entity.OAuthAuthorizationCode),
+		revoked: make(map[string]*entity.RevokedToken),
+		nextID:  1,
+	}
+}
+
+func (r *mockOAuthRepository) CreateClient(ctx context.Context, client *entity.OAuthClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.clients[client.ClientID]; exists {
+		return entity.ErrDuplicate
+	}
+
+	client.ID = r.nextID
+	r.nextID++
+	r.clients[client.ClientID] = client
+	return nil
+}
+
+func (r *mockOAuthRepository) GetClientByClientID(ctx context.Context, clientID string) (*entity.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, exists := r.clients[clientID]
+	if !exists {
+		return nil, entity.ErrNotFound
+	}
+	return client, nil
+}
+
+func (r *mockOAuthRepository) CreateAuthorizationCode(ctx context.Context, code *entity.OAuthAuthorizationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code.ID = r.nextID
+	r.nextID++
+	r.codes[code.CodeHash] = code
+	return nil
+}
+
+func (r *mockOAuthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*entity.OAuthAuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, exists := r.codes[codeHash]
+	if !exists {
+		return nil, entity.ErrNotFound
+	}
+	delete(r.codes, codeHash)
+	return code, nil
+}
+
+func (r *mockOAuthRepository) RevokeToken(ctx context.Context, token *entity.RevokedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revoked[token.JTI] = token
+	return nil
+}
+
+func (r *mockOAuthRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.revoked[jti]
+	return exists && token.ExpiresAt.After(time.Now()), nil
+}
+
+func (r *mockOAuthRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for hash, code := range r.codes {
+		if code.ExpiresAt.Before(before) {
+			delete(r.codes, hash)
+			deleted++
+		}
+	}
+	for jti, token := range r.revoked {
+		if token.ExpiresAt.Before(before) {
+			delete(r.revoked, jti)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -1,34 +1,43 @@
 package repository
 
 import (
+	"context"
 	"gin-server-template/internal/config"
 	"gin-server-template/internal/entity"
 	"gin-server-template/internal/repository/mongodb"
 	"gin-server-template/internal/repository/mysql"
+	"sync"
+	"time"
 )
 
 // UserRepository 用户数据访问接口
+//
+// 记录不存在时返回entity.ErrNotFound，违反唯一约束时返回entity.ErrDuplicate。
 type UserRepository interface {
-	// Create 创建用户
-	Create(user *entity.User) error
+	// Create 创建用户，用户名或邮箱重复时返回entity.ErrDuplicate
+	Create(ctx context.Context, user *entity.User) error
 
 	// GetByID 根据ID获取用户
-	GetByID(id uint) (*entity.User, error)
+	GetByID(ctx context.Context, id uint) (*entity.User, error)
 
 	// GetByUsername 根据用户名获取用户
-	GetByUsername(username string) (*entity.User, error)
+	GetByUsername(ctx context.Context, username string) (*entity.User, error)
 
 	// ExistsByUsername 检查用户名是否存在
-	ExistsByUsername(username string) (bool, error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
 
 	// ExistsByEmail 检查邮箱是否存在
-	ExistsByEmail(email string) (bool, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
 
-	// Update 更新用户信息
-	Update(user *entity.User) error
+	// Update 按版本号条件更新用户信息并将版本号加1，用户不存在时返回entity.ErrNotFound，
+	// 版本号已被其他更新修改时返回entity.ErrConflict
+	Update(ctx context.Context, user *entity.User) error
+
+	// UpdateFields 与Update相同，但只写入fields指定的列（使用数据库列名），用于部分更新
+	UpdateFields(ctx context.Context, user *entity.User, fields ...string) error
 
 	// Delete 删除用户
-	Delete(id uint) error
+	Delete(ctx context.Context, id uint) error
 }
 
 // NewUserRepository 创建用户仓库实例
@@ -48,12 +57,16 @@ func NewUserRepository() UserRepository {
 		}
 	}
 
-	// 默认返回模拟实现
-	return newMockUserRepository()
+	// 默认返回共享的模拟实现，保证各服务看到同一份数据
+	return mockUserRepo
 }
 
+// mockUserRepo 共享的模拟实现实例
+var mockUserRepo = newMockUserRepository()
+
 // 模拟实现，用于开发和测试
 type mockUserRepository struct {
+	mu     sync.Mutex
 	users  map[uint]*entity.User
 	nextID uint
 }
@@ -65,31 +78,53 @@ func newMockUserRepository() *mockUserRepository {
 	}
 }
 
-func (r *mockUserRepository) Create(user *entity.User) error {
+func (r *mockUserRepository) Create(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Username == user.Username || (user.Email != "" && existing.Email == user.Email) {
+			return entity.ErrDuplicate
+		}
+	}
+
+	now := time.Now()
 	user.ID = r.nextID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Version = 1
 	r.nextID++
-	r.users[user.ID] = user
+	r.users[user.ID] = copyUser(user)
 	return nil
 }
 
-func (r *mockUserRepository) GetByID(id uint) (*entity.User, error) {
+func (r *mockUserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	user, exists := r.users[id]
 	if !exists {
-		return nil, nil
+		return nil, entity.ErrNotFound
 	}
-	return user, nil
+	return copyUser(user), nil
 }
 
-func (r *mockUserRepository) GetByUsername(username string) (*entity.User, error) {
+func (r *mockUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	for _, user := range r.users {
 		if user.Username == username {
-			return user, nil
+			return copyUser(user), nil
 		}
 	}
-	return nil, nil
+	return nil, entity.ErrNotFound
 }
 
-func (r *mockUserRepository) ExistsByUsername(username string) (bool, error) {
+func (r *mockUserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	for _, user := range r.users {
 		if user.Username == username {
 			return true, nil
@@ -98,7 +133,10 @@ func (r *mockUserRepository) ExistsByUsername(username string) (bool, error) {
 	return false, nil
 }
 
-func (r *mockUserRepository) ExistsByEmail(email string) (bool, error) {
+func (r *mockUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	for _, user := range r.users {
 		if user.Email == email {
 			return true, nil
@@ -107,16 +145,46 @@ func (r *mockUserRepository) ExistsByEmail(email string) (bool, error) {
 	return false, nil
 }
 
-func (r *mockUserRepository) Update(user *entity.User) error {
-	_, exists := r.users[user.ID]
+func (r *mockUserRepository) Update(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.users[user.ID]
 	if !exists {
-		return nil
+		return entity.ErrNotFound
+	}
+	if stored.Version != user.Version {
+		return entity.ErrConflict
 	}
-	r.users[user.ID] = user
+	for _, existing := range r.users {
+		if existing.ID != user.ID && user.Email != "" && existing.Email == user.Email {
+			return entity.ErrDuplicate
+		}
+	}
+	user.UpdatedAt = time.Now()
+	user.Version++
+	r.users[user.ID] = copyUser(user)
 	return nil
 }
 
-func (r *mockUserRepository) Delete(id uint) error {
+// UpdateFields 版本号检查保证记录在读取后未被修改，整体写入与只写入指定字段的结果相同
+func (r *mockUserRepository) UpdateFields(ctx context.Context, user *entity.User, fields ...string) error {
+	return r.Update(ctx, user)
+}
+
+func (r *mockUserRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[id]; !exists {
+		return entity.ErrNotFound
+	}
 	delete(r.users, id)
 	return nil
 }
+
+// copyUser 复制用户，避免调用方修改返回值时绕过版本号检查直接改动存储的数据
+func copyUser(user *entity.User) *entity.User {
+	copied := *user
+	return &copied
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/repository"
+	"gin-server-template/pkg/logger"
+	"gin-server-template/pkg/requestid"
+	"strings"
+)
+
+// 审计事件查询的分页参数
+const (
+	defaultAuditPageSize = 20
+	maxAuditPageSize     = 100
+)
+
+// maxUserAgentLength 审计事件中保存的User-Agent最大长度
+const maxUserAgentLength = 255
+
+// AuditService 审计服务
+type AuditService struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditService 创建审计服务实例
+func NewAuditService() *AuditService {
+	return &AuditService{
+		auditRepo: repository.NewAuditRepository(),
+	}
+}
+
+// Record 记录审计事件
+//
+// 请求ID从上下文中获取。保存失败只记录日志，不影响业务请求的结果。
+func (s *AuditService) Record(ctx context.Context, event *entity.AuditEvent) {
+	if event.RequestID == "" {
+		event.RequestID = requestid.FromContext(ctx)
+	}
+	if len(event.UserAgent) > maxUserAgentLength {
+		event.UserAgent = strings.ToValidUTF8(event.UserAgent[:maxUserAgentLength], "")
+	}
+
+	if err := s.auditRepo.Create(ctx, event); err != nil {
+		logger.FromContext(ctx).Error("保存审计事件失败",
+			"action", event.Action,
+			"actor_id", event.ActorID,
+			"error", err,
+		)
+	}
+}
+
+// List 按条件分页查询审计事件，未设置或超出范围的分页参数会被修正后写回filter
+func (s *AuditService) List(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEvent, int64, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = defaultAuditPageSize
+	}
+	if filter.PageSize > maxAuditPageSize {
+		filter.PageSize = maxAuditPageSize
+	}
+
+	events, total, err := s.auditRepo.List(ctx, *filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if events == nil {
+		events = []*entity.AuditEvent{}
+	}
+	return events, total, nil
+}
+
+// MarshalChanges 将字段变更序列化为审计事件的Changes，没有变更时返回空字符串
+func MarshalChanges(changes map[string]entity.AuditChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"gin-server-template/internal/config"
+	"gin-server-template/internal/entity"
+	"gin-server-template/pkg/imaging"
+	"gin-server-template/pkg/logger"
+	"gin-server-template/pkg/response"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxAvatarPixels 头像原图的像素数上限，解码后约占64MB内存
+const maxAvatarPixels = 4096 * 4096
+
+// defaultAvatarSizes 未配置时生成的头像边长
+var defaultAvatarSizes = []int{256, 64}
+
+// UpdateAvatar 处理上传的头像图片并保存为用户头像
+//
+// 图片类型按内容识别，支持JPEG、PNG、GIF和WebP；每个配置的尺寸都从中央裁剪为正方形并重新编码，
+// 保存在avatars/{用户ID}/{随机目录}/{边长}.{jpg|png}，第一个尺寸的地址写入user.Avatar。
+// 各尺寸的键记录在user.AvatarKeys中。文件超过avatar.max_size时返回413，更新成功后删除该用户之前上传的头像。
+func (s *UserService) UpdateAvatar(ctx context.Context, user *entity.User, file io.Reader) (err error) {
+	ctx, span := startSpan(ctx, "UserService.UpdateAvatar")
+	defer func() { endSpan(span, err) }()
+
+	var avatarCfg config.AvatarConfig
+	if cfg, err := config.LoadConfig("configs/config.yaml"); err == nil {
+		avatarCfg = cfg.Avatar
+	}
+	sizes := avatarCfg.Sizes
+	if len(sizes) == 0 {
+		sizes = defaultAvatarSizes
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, avatarCfg.SizeLimit()+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > avatarCfg.SizeLimit() {
+		return response.ErrTooLarge
+	}
+
+	img, err := imaging.Decode(data, maxAvatarPixels)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrUnsupported):
+			return response.ErrUnsupported.WithCause(err)
+		case errors.Is(err, imaging.ErrTooLarge):
+			return response.ErrTooLarge.WithCause(err)
+		default:
+			return ErrInvalidAvatar.WithCause(err)
+		}
+	}
+
+	token, err := randomToken(8)
+	if err != nil {
+		return err
+	}
+	dir := fmt.Sprintf("avatars/%d/%s", user.ID, token)
+
+	var keys []string
+	var avatarURL string
+	for _, size := range sizes {
+		encoded, contentType, ext, err := imaging.Encode(imaging.Square(img, size))
+		if err != nil {
+			s.deleteObjects(ctx, keys)
+			return err
+		}
+		key := dir + "/" + strconv.Itoa(size) + ext
+		url, err := s.storage.Put(ctx, key, bytes.NewReader(encoded), int64(len(encoded)), contentType)
+		if err != nil {
+			s.deleteObjects(ctx, keys)
+			return err
+		}
+		keys = append(keys, key)
+		if avatarURL == "" {
+			avatarURL = url
+		}
+	}
+
+	previous, previousKeys := user.Avatar, user.AvatarKeys
+	user.Avatar, user.AvatarKeys = avatarURL, strings.Join(keys, " ")
+	if err := s.userRepo.UpdateFields(ctx, user, "avatar", "avatar_keys"); err != nil {
+		user.Avatar, user.AvatarKeys = previous, previousKeys
+		s.deleteObjects(ctx, keys)
+		return translateUserError(err)
+	}
+
+	s.deleteObjects(ctx, s.uploadedAvatarKeys(user.ID, previous, previousKeys))
+	return nil
+}
+
+// DeleteAvatar 清除用户头像，更新成功后删除之前上传的头像文件
+func (s *UserService) DeleteAvatar(ctx context.Context, user *entity.User) (err error) {
+	ctx, span := startSpan(ctx, "UserService.DeleteAvatar")
+	defer func() { endSpan(span, err) }()
+
+	if user.Avatar == "" && user.AvatarKeys == "" {
+		return nil
+	}
+
+	previous, previousKeys := user.Avatar, user.AvatarKeys
+	user.Avatar, user.AvatarKeys = "", ""
+	if err := s.userRepo.UpdateFields(ctx, user, "avatar", "avatar_keys"); err != nil {
+		user.Avatar, user.AvatarKeys = previous, previousKeys
+		return translateUserError(err)
+	}
+
+	s.deleteObjects(ctx, s.uploadedAvatarKeys(user.ID, previous, previousKeys))
+	return nil
+}
+
+// uploadedAvatarKeys 返回用户之前上传的头像文件的键
+//
+// 上传时记录了全部尺寸的键，直接使用；没有记录的早期数据只能从地址还原出第一个尺寸，
+// 并且只删除该用户目录下的文件，外部地址或其他用户的头像不受影响。
+func (s *UserService) uploadedAvatarKeys(userID uint, avatar, keys string) []string {
+	if keys != "" {
+		return strings.Fields(keys)
+	}
+	if key, ok := s.storage.Key(avatar); ok && strings.HasPrefix(key, fmt.Sprintf("avatars/%d/", userID)) {
+		return []string{key}
+	}
+	return nil
+}
+
+// deleteObjects 删除对象存储中的文件，请求已结束或取消时同样执行，失败时只记录日志
+func (s *UserService) deleteObjects(ctx context.Context, keys []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			logger.FromContext(ctx).Warn("删除对象失败", "key", key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"gin-server-template/pkg/storage"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateAvatar(t *testing.T) {
+	s, dir := newAvatarTestService(t)
+	ctx := context.Background()
+	user := createTestUser(t, "avatar_user")
+
+	if err := s.UpdateAvatar(ctx, user, bytes.NewReader(testPNG(t))); err != nil {
+		t.Fatalf("UpdateAvatar() error = %v", err)
+	}
+	first := strings.Fields(user.AvatarKeys)
+	if len(first) != len(defaultAvatarSizes) {
+		t.Fatalf("AvatarKeys = %q, want %d个键", user.AvatarKeys, len(defaultAvatarSizes))
+	}
+	if user.Avatar != "/uploads/"+first[0] {
+		t.Errorf("Avatar = %q, want /uploads/%s", user.Avatar, first[0])
+	}
+	assertObjects(t, dir, first, true)
+
+	// 按记录的键删除旧头像，即使尺寸配置已经改变
+	stale := strings.TrimSuffix(first[0], "256.png") + "512.png"
+	if _, err := s.storage.Put(ctx, stale, bytes.NewReader([]byte("x")), 1, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	user.AvatarKeys += " " + stale
+	if err := s.UpdateUserFields(ctx, user, "avatar_keys"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpdateAvatar(ctx, user, bytes.NewReader(testPNG(t))); err != nil {
+		t.Fatalf("UpdateAvatar() error = %v", err)
+	}
+	second := strings.Fields(user.AvatarKeys)
+	assertObjects(t, dir, append(first, stale), false)
+	assertObjects(t, dir, second, true)
+
+	if err := s.DeleteAvatar(ctx, user); err != nil {
+		t.Fatalf("DeleteAvatar() error = %v", err)
+	}
+	if user.Avatar != "" || user.AvatarKeys != "" {
+		t.Errorf("Avatar = %q, AvatarKeys = %q, want empty", user.Avatar, user.AvatarKeys)
+	}
+	assertObjects(t, dir, second, false)
+}
+
+func TestUploadedAvatarKeys(t *testing.T) {
+	s, _ := newAvatarTestService(t)
+
+	tests := []struct {
+		name   string
+		avatar string
+		keys   string
+		want   []string
+	}{
+		{"记录的键", "/uploads/avatars/7/a/256.png", "avatars/7/a/256.png avatars/7/a/64.png", []string{"avatars/7/a/256.png", "avatars/7/a/64.png"}},
+		{"早期数据只还原第一个尺寸", "/uploads/avatars/7/a/256.png", "", []string{"avatars/7/a/256.png"}},
+		{"其他用户的头像", "/uploads/avatars/8/a/256.png", "", nil},
+		{"外部地址", "https://cdn.example.com/avatars/7/a/256.png", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.uploadedAvatarKeys(7, tt.avatar, tt.keys)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("uploadedAvatarKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newAvatarTestService 创建使用临时目录存储头像的用户服务
+func newAvatarTestService(t *testing.T) (*UserService, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	s := NewUserService()
+	s.storage = storage.NewLocalStorage(dir, "/uploads")
+	return s, dir
+}
+
+// testPNG 生成带透明区域的测试图片
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 200))
+	img.Set(10, 10, color.NRGBA{R: 255, A: 128})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// assertObjects 检查对象是否存在于存储目录中
+func assertObjects(t *testing.T, dir string, keys []string, exist bool) {
+	t.Helper()
+
+	for _, key := range keys {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(key)))
+		if exist && err != nil {
+			t.Errorf("对象%s应存在: %v", key, err)
+		}
+		if !exist && !os.IsNotExist(err) {
+			t.Errorf("对象%s应已删除", key)
+		}
+	}
+}
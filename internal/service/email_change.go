@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/config"
+	"gin-server-template/internal/entity"
+	"gin-server-template/pkg/i18n"
+	"gin-server-template/pkg/logger"
+	"gin-server-template/pkg/mailer"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// emailChangeRetries 确认邮箱时因资料被并发修改而重试的次数
+	emailChangeRetries = 3
+
+	// defaultEmailChangeExpire 未配置时确认链接的有效期
+	defaultEmailChangeExpire = 24 * time.Hour
+)
+
+// RequestEmailChange 申请将用户邮箱修改为newEmail，邮件按locale指定的语言发送
+//
+// 新邮箱不能已被其他账号使用。申请成功后新邮箱记录在PendingEmail中，确认链接发送到新邮箱，
+// 同时通知原邮箱；重复申请会使之前的确认链接失效。user按版本号更新，成功后版本号随之变化。
+// 确认邮件发送失败时撤销本次申请，资料中不会留下无法确认的待确认邮箱。
+func (s *UserService) RequestEmailChange(ctx context.Context, user *entity.User, newEmail, locale string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.RequestEmailChange")
+	defer func() { endSpan(span, err) }()
+
+	exist, err := s.userRepo.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return ErrEmailExists
+	}
+
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		return err
+	}
+	expire := time.Duration(cfg.EmailChange.TokenExpire) * time.Second
+	if expire <= 0 {
+		expire = defaultEmailChangeExpire
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	if err := s.emailChangeRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+	err = s.emailChangeRepo.Create(ctx, &entity.EmailChange{
+		UserID:    user.ID,
+		Email:     newEmail,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(expire),
+	})
+	if err != nil {
+		return err
+	}
+
+	user.PendingEmail = newEmail
+	if err := s.userRepo.UpdateFields(ctx, user, "pending_email"); err != nil {
+		return translateUserError(err)
+	}
+
+	// 确认邮件发送失败时新邮箱无法确认，撤销申请后返回错误让用户重新申请
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      []string{newEmail},
+		Subject: i18n.T(locale, "mail.email_change.confirm_subject", "确认修改邮箱"),
+		Body: i18n.T(locale, "mail.email_change.confirm_body",
+			"你正在将账号 %s 的邮箱修改为此地址，请在%s内完成确认：\n\n%s\n\n如果这不是你本人的操作，请忽略本邮件。",
+			user.Username, formatExpire(locale, expire), confirmLink(locale, cfg.EmailChange.ConfirmURL, token)),
+	}); err != nil {
+		s.cancelEmailChange(ctx, user)
+		return err
+	}
+
+	if user.Email == "" {
+		return nil
+	}
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      []string{user.Email},
+		Subject: i18n.T(locale, "mail.email_change.notice_subject", "邮箱修改通知"),
+		Body: i18n.T(locale, "mail.email_change.notice_body",
+			"你的账号 %s 申请将邮箱修改为 %s，新邮箱确认后本邮箱将不再接收该账号的邮件。\n\n如果这不是你本人的操作，请立即修改密码。",
+			user.Username, newEmail),
+	}); err != nil {
+		logger.FromContext(ctx).Warn("发送邮箱修改通知失败", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// cancelEmailChange 撤销未能发出确认邮件的申请，删除确认令牌并清空待确认邮箱
+//
+// 撤销失败只记录日志：令牌从未发出，残留的待确认邮箱会在下次申请时被覆盖。
+func (s *UserService) cancelEmailChange(ctx context.Context, user *entity.User) {
+	log := logger.FromContext(ctx)
+	if err := s.emailChangeRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		log.Warn("撤销邮箱修改申请失败", "user_id", user.ID, "error", err)
+	}
+
+	user.PendingEmail = ""
+	if err := s.userRepo.UpdateFields(ctx, user, "pending_email"); err != nil {
+		log.Warn("清空待确认邮箱失败", "user_id", user.ID, "error", err)
+	}
+}
+
+// ConfirmEmailChange 使用确认令牌完成邮箱修改，返回修改后的用户和原邮箱
+//
+// 令牌只能使用一次：新邮箱与待确认邮箱一起按版本号更新，并发确认时只有一个请求能够更新成功，
+// 其余请求重新读取后发现待确认邮箱已清空而失败；更新成功后才删除令牌，更新失败时令牌仍然有效。
+// 期间新邮箱被其他账号抢先使用时，由唯一索引保证返回ErrEmailExists。
+func (s *UserService) ConfirmEmailChange(ctx context.Context, token string) (_ *entity.User, previous string, err error) {
+	ctx, span := startSpan(ctx, "UserService.ConfirmEmailChange")
+	defer func() { endSpan(span, err) }()
+
+	change, err := s.emailChangeRepo.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, "", ErrEmailChangeInvalid
+		}
+		return nil, "", err
+	}
+	if time.Now().After(change.ExpiresAt) {
+		return nil, "", ErrEmailChangeInvalid
+	}
+
+	for attempt := 1; ; attempt++ {
+		user, err := s.userRepo.GetByID(ctx, change.UserID)
+		if err != nil {
+			return nil, "", translateUserError(err)
+		}
+		// 申请之后又改为其他邮箱或令牌已被使用，确认链接作废
+		if user.PendingEmail != change.Email {
+			return nil, "", ErrEmailChangeInvalid
+		}
+
+		exist, err := s.userRepo.ExistsByEmail(ctx, change.Email)
+		if err != nil {
+			return nil, "", err
+		}
+		if exist {
+			return nil, "", ErrEmailExists
+		}
+
+		previous = user.Email
+		user.Email = change.Email
+		user.PendingEmail = ""
+		err = s.userRepo.UpdateFields(ctx, user, "email", "pending_email")
+		if errors.Is(err, entity.ErrConflict) && attempt < emailChangeRetries {
+			continue
+		}
+		if err != nil {
+			return nil, "", translateUserError(err)
+		}
+
+		// 待确认邮箱已清空，令牌删除失败也无法再次使用
+		if err := s.emailChangeRepo.DeleteByUserID(ctx, user.ID); err != nil {
+			logger.FromContext(ctx).Warn("删除邮箱确认令牌失败", "user_id", user.ID, "error", err)
+		}
+		return user, previous, nil
+	}
+}
+
+// confirmLink 生成确认链接，未配置确认页面地址时直接给出令牌
+func confirmLink(locale, confirmURL, token string) string {
+	if confirmURL == "" {
+		return i18n.T(locale, "mail.email_change.token", "确认令牌：%s", token)
+	}
+	return strings.ReplaceAll(confirmURL, "{token}", url.QueryEscape(token))
+}
+
+// formatExpire 将有效期格式化为便于阅读的文字
+func formatExpire(locale string, d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return i18n.T(locale, "mail.duration.hours", "%d小时", d/time.Hour)
+	}
+	return i18n.T(locale, "mail.duration.minutes", "%d分钟", d/time.Minute)
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/repository"
+	"gin-server-template/pkg/i18n"
+	"gin-server-template/pkg/mailer"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+// tokenPattern 未配置确认页面地址时邮件正文中的确认令牌
+var tokenPattern = regexp.MustCompile(`确认令牌：(\S+)`)
+
+func TestEmailChange(t *testing.T) {
+	s, sent := newEmailChangeTestService(nil)
+	ctx := context.Background()
+	user := createTestUser(t, "email_change")
+
+	if err := s.RequestEmailChange(ctx, user, "email_change_new@example.com", "zh"); err != nil {
+		t.Fatalf("RequestEmailChange() error = %v", err)
+	}
+	if user.PendingEmail != "email_change_new@example.com" {
+		t.Errorf("PendingEmail = %q", user.PendingEmail)
+	}
+
+	// 确认邮件发送到新邮箱，通知发送到原邮箱
+	messages := sent.all()
+	if len(messages) != 2 || messages[0].To[0] != "email_change_new@example.com" || messages[1].To[0] != user.Email {
+		t.Fatalf("邮件 = %+v", messages)
+	}
+	token := extractToken(t, messages[0])
+
+	confirmed, previous, err := s.ConfirmEmailChange(ctx, token)
+	if err != nil {
+		t.Fatalf("ConfirmEmailChange() error = %v", err)
+	}
+	if confirmed.Email != "email_change_new@example.com" || confirmed.PendingEmail != "" || previous != user.Email {
+		t.Errorf("确认后 Email = %q, PendingEmail = %q, previous = %q", confirmed.Email, confirmed.PendingEmail, previous)
+	}
+
+	// 令牌只能使用一次
+	if _, _, err := s.ConfirmEmailChange(ctx, token); !errors.Is(err, ErrEmailChangeInvalid) {
+		t.Errorf("重复确认 error = %v, want ErrEmailChangeInvalid", err)
+	}
+}
+
+func TestEmailChangeSupersededToken(t *testing.T) {
+	s, sent := newEmailChangeTestService(nil)
+	ctx := context.Background()
+	user := createTestUser(t, "email_change_superseded")
+
+	if err := s.RequestEmailChange(ctx, user, "email_change_first@example.com", "zh"); err != nil {
+		t.Fatal(err)
+	}
+	first := extractToken(t, sent.all()[0])
+	if err := s.RequestEmailChange(ctx, user, "email_change_second@example.com", "zh"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := s.ConfirmEmailChange(ctx, first); !errors.Is(err, ErrEmailChangeInvalid) {
+		t.Errorf("被取代的令牌 error = %v, want ErrEmailChangeInvalid", err)
+	}
+}
+
+func TestEmailChangeMailFailure(t *testing.T) {
+	s, sent := newEmailChangeTestService(errors.New("smtp unavailable"))
+	ctx := context.Background()
+	user := createTestUser(t, "email_change_mail_failure")
+
+	if err := s.RequestEmailChange(ctx, user, "email_change_unsent@example.com", "zh"); err == nil {
+		t.Fatal("确认邮件发送失败时应返回错误")
+	}
+
+	// 申请被撤销，资料中不保留待确认邮箱，令牌无法使用
+	stored, err := s.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.PendingEmail != "" {
+		t.Errorf("PendingEmail = %q, want empty", stored.PendingEmail)
+	}
+	token := extractToken(t, sent.all()[0])
+	if _, _, err := s.ConfirmEmailChange(ctx, token); !errors.Is(err, ErrEmailChangeInvalid) {
+		t.Errorf("ConfirmEmailChange() error = %v, want ErrEmailChangeInvalid", err)
+	}
+}
+
+func TestEmailChangeTokenKeptOnFailure(t *testing.T) {
+	s, sent := newEmailChangeTestService(nil)
+	ctx := context.Background()
+	user := createTestUser(t, "email_change_taken")
+
+	if err := s.RequestEmailChange(ctx, user, "email_change_taken_new@example.com", "zh"); err != nil {
+		t.Fatal(err)
+	}
+	token := extractToken(t, sent.all()[0])
+
+	// 确认之前新邮箱被其他账号使用，确认失败但不消耗令牌
+	users := repository.NewUserRepository()
+	other := createTestUser(t, "email_change_taken_other")
+	other.Email = "email_change_taken_new@example.com"
+	if err := users.Update(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.ConfirmEmailChange(ctx, token); !errors.Is(err, ErrEmailExists) {
+		t.Fatalf("ConfirmEmailChange() error = %v, want ErrEmailExists", err)
+	}
+
+	// 其他账号释放邮箱后，同一令牌仍可完成确认
+	if err := users.Delete(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.ConfirmEmailChange(ctx, token); err != nil {
+		t.Errorf("ConfirmEmailChange() error = %v", err)
+	}
+}
+
+func TestEmailChangeLocale(t *testing.T) {
+	i18n.Register("en", map[string]string{
+		"mail.email_change.confirm_subject": "Confirm your new email address",
+		"mail.email_change.notice_subject":  "Email change requested",
+		"mail.duration.hours":               "%d hour(s)",
+	})
+
+	s, sent := newEmailChangeTestService(nil)
+	user := createTestUser(t, "email_change_locale")
+	if err := s.RequestEmailChange(context.Background(), user, "email_change_locale_new@example.com", "en"); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := sent.all()
+	if messages[0].Subject != "Confirm your new email address" || messages[1].Subject != "Email change requested" {
+		t.Errorf("标题 = %q, %q", messages[0].Subject, messages[1].Subject)
+	}
+	// 正文未翻译时回退到默认语言，参数同样按语言格式化
+	if !regexp.MustCompile(`请在1 hour\(s\)内完成确认`).MatchString(messages[0].Body) {
+		t.Errorf("正文 = %q", messages[0].Body)
+	}
+}
+
+// newEmailChangeTestService 创建使用测试邮件发送器的用户服务，sendErr非空时确认邮件发送失败
+func newEmailChangeTestService(sendErr error) (*UserService, *testMailer) {
+	s := NewUserService()
+	m := &testMailer{err: sendErr}
+	s.mailer = m
+	return s, m
+}
+
+// extractToken 从确认邮件中取出确认令牌
+func extractToken(t *testing.T, msg *mailer.Message) string {
+	t.Helper()
+
+	match := tokenPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("邮件中没有确认令牌: %q", msg.Body)
+	}
+	return match[1]
+}
+
+// testMailer 记录发送的邮件，err非空时所有邮件都发送失败
+type testMailer struct {
+	mu   sync.Mutex
+	sent []*mailer.Message
+	err  error
+}
+
+func (m *testMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, msg)
+	return m.err
+}
+
+// all 返回已发送的邮件
+func (m *testMailer) all() []*mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*mailer.Message(nil), m.sent...)
+}
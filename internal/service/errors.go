@@ -0,0 +1,45 @@
+package service
+
+import (
+	"gin-server-template/pkg/response"
+	"net/http"
+)
+
+// 用户模块业务错误码
+const (
+	CodeUserNotFound       = 10001
+	CodeUsernameExists     = 10002
+	CodeEmailExists        = 10003
+	CodeUserExists         = 10004
+	CodeInvalidCredentials = 10005
+	CodeUserConflict       = 10006
+	CodeEmailChangeInvalid = 10007
+	CodeInvalidAvatar      = 10008
+)
+
+// 用户服务返回的业务错误
+var (
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = response.NewError(http.StatusNotFound, CodeUserNotFound, "用户不存在")
+
+	// ErrUsernameExists 用户名已存在
+	ErrUsernameExists = response.NewError(http.StatusConflict, CodeUsernameExists, "用户名已存在")
+
+	// ErrEmailExists 邮箱已被使用
+	ErrEmailExists = response.NewError(http.StatusConflict, CodeEmailExists, "邮箱已被使用")
+
+	// ErrUserExists 用户名或邮箱已被使用，用于无法区分具体冲突字段的并发注册
+	ErrUserExists = response.NewError(http.StatusConflict, CodeUserExists, "用户名或邮箱已被使用")
+
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = response.NewError(http.StatusUnauthorized, CodeInvalidCredentials, "用户名或密码错误")
+
+	// ErrUserConflict 用户信息已被其他请求修改
+	ErrUserConflict = response.NewError(http.StatusConflict, CodeUserConflict, "用户信息已被修改，请刷新后重试")
+
+	// ErrEmailChangeInvalid 邮箱确认令牌不存在、已使用、已过期或已被新的申请取代
+	ErrEmailChangeInvalid = response.NewError(http.StatusBadRequest, CodeEmailChangeInvalid, "邮箱确认链接无效或已过期")
+
+	// ErrInvalidAvatar 上传的头像图片已损坏或无法解码
+	ErrInvalidAvatar = response.NewError(http.StatusBadRequest, CodeInvalidAvatar, "无法识别的头像图片")
+)
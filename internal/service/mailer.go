@@ -0,0 +1,23 @@
+package service
+
+import (
+	"gin-server-template/internal/config"
+	"gin-server-template/pkg/mailer"
+)
+
+// newMailer 根据配置创建邮件发送器，读取配置失败时只将邮件写入日志
+func newMailer() mailer.Mailer {
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		return mailer.NewLogMailer("")
+	}
+
+	return mailer.New(mailer.Options{
+		Driver:   cfg.Mail.Driver,
+		Host:     cfg.Mail.Host,
+		Port:     cfg.Mail.Port,
+		Username: cfg.Mail.Username,
+		Password: cfg.Mail.Password,
+		From:     cfg.Mail.From,
+	})
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gin-server-template/internal/config"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/repository"
+	"gin-server-template/pkg/password"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OAuth2授权类型
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// 权限范围，注册客户端时只能申请oauth.scopes中配置的权限范围
+const (
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// PKCE挑战方式
+const (
+	CodeChallengeMethodPlain = "plain"
+	CodeChallengeMethodS256  = "S256"
+)
+
+// OAuthError OAuth2协议错误，Code取值见RFC 6749第5.2节
+//
+// Description为默认的中文描述，可以包含格式化占位符；返回给客户端的描述按请求语言
+// 从消息目录的oauth.error.<Key>中查找，缺失时使用Description。
+type OAuthError struct {
+	Code        string
+	Key         string
+	Description string
+	Args        []interface{}
+}
+
+// Error 实现error接口
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + fmt.Sprintf(e.Description, e.Args...)
+}
+
+func newOAuthError(code, key, description string, args ...interface{}) *OAuthError {
+	return &OAuthError{Code: code, Key: key, Description: description, Args: args}
+}
+
+// OAuthService OAuth2授权服务
+type OAuthService struct {
+	oauthRepo    repository.OAuthRepository
+	userRepo     repository.UserRepository
+	tokenService *TokenService
+	hasher       password.PasswordHasher
+}
+
+// NewOAuthService 创建OAuth2授权服务实例
+func NewOAuthService() *OAuthService {
+	return &OAuthService{
+		oauthRepo:    repository.NewOAuthRepository(),
+		userRepo:     repository.NewUserRepository(),
+		tokenService: NewTokenService(),
+		hasher:       newPasswordHasher(),
+	}
+}
+
+// TokenResult 令牌端点的签发结果
+type TokenResult struct {
+	AccessToken string
+	ExpiresIn   time.Duration
+	Scope       string
+}
+
+// RegisterClient 注册客户端并返回明文密钥（仅机密客户端有密钥，且只在此处返回一次）
+func (s *OAuthService) RegisterClient(ctx context.Context, client *entity.OAuthClient) (string, error) {
+	for _, grantType := range strings.Fields(client.GrantTypes) {
+		switch grantType {
+		case GrantTypeAuthorizationCode:
+			if client.RedirectURIs == "" {
+				return "", newOAuthError("invalid_client_metadata", "redirect_uri_required", "授权码模式必须登记回调地址")
+			}
+		case GrantTypeClientCredentials:
+			if !client.Confidential {
+				return "", newOAuthError("invalid_client_metadata", "public_client_credentials", "公共客户端不能使用客户端凭证模式")
+			}
+		default:
+			return "", newOAuthError("invalid_client_metadata", "unsupported_grant_type", "不支持的授权类型: %s", grantType)
+		}
+	}
+
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		return "", err
+	}
+	for _, scope := range strings.Fields(client.Scopes) {
+		if !slices.Contains(cfg.OAuth.Scopes, scope) {
+			return "", newOAuthError("invalid_client_metadata", "unsupported_scope", "不支持的权限范围: %s", scope)
+		}
+	}
+
+	clientID, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	client.ClientID = clientID
+
+	// 机密客户端生成密钥，仅保存哈希值
+	var secret string
+	if client.Confidential {
+		secret, err = randomToken(32)
+		if err != nil {
+			return "", err
+		}
+		client.ClientSecret, err = s.hasher.Hash(secret)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.oauthRepo.CreateClient(ctx, client); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// GetClient 获取客户端并校验回调地址，用于授权端点在重定向前的校验
+func (s *OAuthService) GetClient(ctx context.Context, clientID, redirectURI string) (*entity.OAuthClient, error) {
+	client, err := s.oauthRepo.GetClientByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, newOAuthError("invalid_client", "client_not_found", "客户端不存在")
+		}
+		return nil, err
+	}
+	if !client.HasRedirectURI(redirectURI) {
+		return nil, newOAuthError("invalid_request", "redirect_uri_mismatch", "回调地址未登记")
+	}
+	return client, nil
+}
+
+// Authorize 为已登录用户签发授权码
+func (s *OAuthService) Authorize(ctx context.Context, userID uint, client *entity.OAuthClient, redirectURI, scope, codeChallenge, codeChallengeMethod string) (string, error) {
+	if !client.HasGrantType(GrantTypeAuthorizationCode) {
+		return "", newOAuthError("unauthorized_client", "authorization_code_not_allowed", "客户端不允许使用授权码模式")
+	}
+
+	scope, err := resolveScope(client, scope)
+	if err != nil {
+		return "", err
+	}
+
+	// 公共客户端必须使用PKCE
+	if codeChallenge == "" && !client.Confidential {
+		return "", newOAuthError("invalid_request", "code_challenge_required", "公共客户端必须提供code_challenge")
+	}
+	if codeChallenge != "" {
+		if codeChallengeMethod == "" {
+			codeChallengeMethod = CodeChallengeMethodPlain
+		}
+		if codeChallengeMethod != CodeChallengeMethodPlain && codeChallengeMethod != CodeChallengeMethodS256 {
+			return "", newOAuthError("invalid_request", "unsupported_challenge_method", "不支持的code_challenge_method")
+		}
+	}
+
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		return "", err
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.oauthRepo.CreateAuthorizationCode(ctx, &entity.OAuthAuthorizationCode{
+		CodeHash:            hashToken(code),
+		ClientID:            client.ClientID,
+		UserID:              userID,
+		RedirectURI:         redirectURI,
+		Scope:               scope,
+		CodeChallenge:       codeChallenge,
+		CodeChallengeMethod: codeChallengeMethod,
+		ExpiresAt:           time.Now().Add(time.Duration(cfg.OAuth.CodeExpire) * time.Second),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// AuthenticateClient 验证客户端身份，公共客户端只需提供客户端标识
+func (s *OAuthService) AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*entity.OAuthClient, error) {
+	client, err := s.oauthRepo.GetClientByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, newOAuthError("invalid_client", "client_auth_failed", "客户端认证失败")
+		}
+		return nil, err
+	}
+
+	if client.Confidential {
+		ok, err := s.hasher.Verify(client.ClientSecret, clientSecret)
+		if err != nil {
+			return nil, fmt.Errorf("校验客户端%s的密钥失败: %w", client.ClientID, err)
+		}
+		if !ok {
+			return nil, newOAuthError("invalid_client", "client_auth_failed", "客户端认证失败")
+		}
+	}
+
+	return client, nil
+}
+
+// ExchangeAuthorizationCode 使用授权码兑换访问令牌
+func (s *OAuthService) ExchangeAuthorizationCode(ctx context.Context, client *entity.OAuthClient, code, redirectURI, codeVerifier string) (*TokenResult, error) {
+	if !client.HasGrantType(GrantTypeAuthorizationCode) {
+		return nil, newOAuthError("unauthorized_client", "authorization_code_not_allowed", "客户端不允许使用授权码模式")
+	}
+
+	authCode, err := s.oauthRepo.ConsumeAuthorizationCode(ctx, hashToken(code))
+	if err != nil && !errors.Is(err, entity.ErrNotFound) {
+		return nil, err
+	}
+	if authCode == nil || time.Now().After(authCode.ExpiresAt) {
+		return nil, newOAuthError("invalid_grant", "invalid_code", "授权码无效或已过期")
+	}
+	if authCode.ClientID != client.ClientID || authCode.RedirectURI != redirectURI {
+		return nil, newOAuthError("invalid_grant", "code_mismatch", "授权码与客户端或回调地址不匹配")
+	}
+	if authCode.CodeChallenge != "" && !verifyCodeChallenge(authCode.CodeChallenge, authCode.CodeChallengeMethod, codeVerifier) {
+		return nil, newOAuthError("invalid_grant", "invalid_code_verifier", "code_verifier校验失败")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, authCode.UserID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, newOAuthError("invalid_grant", "user_not_found", "授权用户不存在")
+		}
+		return nil, err
+	}
+
+	token, ttl, err := s.tokenService.GenerateAccessToken(user, client, authCode.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResult{AccessToken: token, ExpiresIn: ttl, Scope: authCode.Scope}, nil
+}
+
+// ClientCredentials 使用客户端凭证模式签发访问令牌
+func (s *OAuthService) ClientCredentials(ctx context.Context, client *entity.OAuthClient, scope string) (*TokenResult, error) {
+	if !client.Confidential || !client.HasGrantType(GrantTypeClientCredentials) {
+		return nil, newOAuthError("unauthorized_client", "client_credentials_not_allowed", "客户端不允许使用客户端凭证模式")
+	}
+
+	scope, err := resolveScope(client, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	token, ttl, err := s.tokenService.GenerateAccessToken(nil, client, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResult{AccessToken: token, ExpiresIn: ttl, Scope: scope}, nil
+}
+
+// Introspect 查询令牌状态（RFC 7662），只允许机密客户端查询签发给自己的访问令牌
+//
+// 无效令牌、登录令牌和签发给其他客户端的令牌都返回 {"active": false}，不泄露令牌内容。
+func (s *OAuthService) Introspect(ctx context.Context, client *entity.OAuthClient, token string) (map[string]interface{}, error) {
+	if !client.Confidential {
+		return nil, newOAuthError("unauthorized_client", "introspection_not_allowed", "公共客户端不能内省令牌")
+	}
+
+	inactive := map[string]interface{}{"active": false}
+	claims, err := s.tokenService.Parse(ctx, token)
+	if err != nil {
+		return inactive, nil
+	}
+	if typ, _ := claims["typ"].(string); typ != TokenTypeAccess {
+		return inactive, nil
+	}
+	if clientID, _ := claims["client_id"].(string); clientID != client.ClientID {
+		return inactive, nil
+	}
+
+	result := map[string]interface{}{
+		"active":     true,
+		"token_type": "Bearer",
+	}
+	for _, key := range []string{"scope", "client_id", "username", "sub", "exp", "iat", "iss", "jti"} {
+		if value, ok := claims[key]; ok {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+// Revoke 撤销令牌（RFC 7009）并返回被撤销令牌的jti，无效令牌或签发给其他客户端的令牌直接忽略
+func (s *OAuthService) Revoke(ctx context.Context, client *entity.OAuthClient, token string) (string, error) {
+	claims, err := s.tokenService.Parse(ctx, token)
+	if err != nil {
+		return "", nil
+	}
+
+	if clientID, _ := claims["client_id"].(string); clientID != client.ClientID {
+		return "", nil
+	}
+
+	jti, _ := claims["jti"].(string)
+	return jti, s.tokenService.Revoke(ctx, claims)
+}
+
+// DeleteExpired 删除已过期的授权码和撤销记录，返回删除的记录数
+func (s *OAuthService) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.oauthRepo.DeleteExpired(ctx, time.Now())
+}
+
+// resolveScope 校验请求的权限范围，未指定时使用客户端的全部权限范围
+func resolveScope(client *entity.OAuthClient, scope string) (string, error) {
+	if scope == "" {
+		return client.Scopes, nil
+	}
+	if !client.AllowsScope(scope) {
+		return "", newOAuthError("invalid_scope", "scope_not_allowed", "请求的权限范围超出客户端允许范围")
+	}
+	return scope, nil
+}
+
+// verifyCodeChallenge 按RFC 7636校验code_verifier
+func verifyCodeChallenge(challenge, method, verifier string) bool {
+	if verifier == "" {
+		return false
+	}
+
+	expected := verifier
+	if method == CodeChallengeMethodS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// hashToken 计算令牌的SHA-256摘要，数据库中只保存摘要
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	// RFC 7636附录B中的示例
+	const (
+		verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	)
+
+	tests := []struct {
+		name      string
+		challenge string
+		method    string
+		verifier  string
+		want      bool
+	}{
+		{"S256匹配", challenge, CodeChallengeMethodS256, verifier, true},
+		{"S256不匹配", challenge, CodeChallengeMethodS256, verifier + "x", false},
+		{"S256不接受明文", challenge, CodeChallengeMethodS256, challenge, false},
+		{"plain匹配", verifier, CodeChallengeMethodPlain, verifier, true},
+		{"plain不匹配", verifier, CodeChallengeMethodPlain, challenge, false},
+		{"缺少verifier", challenge, CodeChallengeMethodS256, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.challenge, tt.method, tt.verifier); got != tt.want {
+				t.Errorf("verifyCodeChallenge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeAuthorizationCodePKCE(t *testing.T) {
+	ctx := context.Background()
+	s := NewOAuthService()
+	user := createTestUser(t, "pkce_user")
+	client := registerTestClient(t, s, false, GrantTypeAuthorizationCode)
+
+	const (
+		verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	)
+	redirectURI := client.RedirectURIs
+
+	// 公共客户端必须使用PKCE
+	_, err := s.Authorize(ctx, user.ID, client, redirectURI, "", "", "")
+	assertOAuthError(t, err, "invalid_request")
+
+	// code_verifier错误时授权码作废
+	code, err := s.Authorize(ctx, user.ID, client, redirectURI, "profile", challenge, CodeChallengeMethodS256)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	_, err = s.ExchangeAuthorizationCode(ctx, client, code, redirectURI, "wrong")
+	assertOAuthError(t, err, "invalid_grant")
+	_, err = s.ExchangeAuthorizationCode(ctx, client, code, redirectURI, verifier)
+	assertOAuthError(t, err, "invalid_grant")
+
+	code, err = s.Authorize(ctx, user.ID, client, redirectURI, "profile", challenge, CodeChallengeMethodS256)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	result, err := s.ExchangeAuthorizationCode(ctx, client, code, redirectURI, verifier)
+	if err != nil {
+		t.Fatalf("ExchangeAuthorizationCode() error = %v", err)
+	}
+	if result.Scope != "profile" || result.AccessToken == "" {
+		t.Errorf("ExchangeAuthorizationCode() = %+v", result)
+	}
+
+	claims, err := s.tokenService.Parse(ctx, result.AccessToken)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if claims["typ"] != TokenTypeAccess || claims["client_id"] != client.ClientID {
+		t.Errorf("访问令牌声明 = %v", claims)
+	}
+}
+
+func TestRegisterClientScopes(t *testing.T) {
+	s := NewOAuthService()
+
+	client := &entity.OAuthClient{
+		Name:         "admin",
+		GrantTypes:   GrantTypeClientCredentials,
+		Scopes:       "profile admin",
+		Confidential: true,
+	}
+	_, err := s.RegisterClient(context.Background(), client)
+	assertOAuthError(t, err, "invalid_client_metadata")
+}
+
+func TestIntrospect(t *testing.T) {
+	ctx := context.Background()
+	s := NewOAuthService()
+	owner := registerTestClient(t, s, true, GrantTypeClientCredentials)
+	other := registerTestClient(t, s, true, GrantTypeClientCredentials)
+	public := registerTestClient(t, s, false, GrantTypeAuthorizationCode)
+
+	result, err := s.ClientCredentials(ctx, owner, "")
+	if err != nil {
+		t.Fatalf("ClientCredentials() error = %v", err)
+	}
+	loginToken, err := s.tokenService.GenerateUserToken(createTestUser(t, "introspect_user"))
+	if err != nil {
+		t.Fatalf("GenerateUserToken() error = %v", err)
+	}
+
+	_, err = s.Introspect(ctx, public, result.AccessToken)
+	assertOAuthError(t, err, "unauthorized_client")
+
+	tests := []struct {
+		name   string
+		client *entity.OAuthClient
+		token  string
+		active bool
+	}{
+		{"签发给自己的令牌", owner, result.AccessToken, true},
+		{"签发给其他客户端的令牌", other, result.AccessToken, false},
+		{"登录令牌", owner, loginToken, false},
+		{"无效令牌", owner, "invalid", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Introspect(ctx, tt.client, tt.token)
+			if err != nil {
+				t.Fatalf("Introspect() error = %v", err)
+			}
+			if got["active"] != tt.active {
+				t.Errorf("Introspect() = %v, want active %v", got, tt.active)
+			}
+			if !tt.active && len(got) != 1 {
+				t.Errorf("无效令牌不应返回令牌内容: %v", got)
+			}
+		})
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	s := NewOAuthService()
+	ctx := context.Background()
+	repo := repository.NewOAuthRepository()
+
+	expired := &entity.RevokedToken{JTI: "expired_jti", ExpiresAt: time.Now().Add(-time.Minute)}
+	active := &entity.RevokedToken{JTI: "active_jti", ExpiresAt: time.Now().Add(time.Hour)}
+	for _, token := range []*entity.RevokedToken{expired, active} {
+		if err := repo.RevokeToken(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code := &entity.OAuthAuthorizationCode{CodeHash: hashToken("expired_code"), ExpiresAt: time.Now().Add(-time.Minute)}
+	if err := repo.CreateAuthorizationCode(ctx, code); err != nil {
+		t.Fatal(err)
+	}
+
+	// 过期令牌的撤销记录不再生效
+	if revoked, _ := repo.IsTokenRevoked(ctx, expired.JTI); revoked {
+		t.Error("过期的撤销记录不应生效")
+	}
+
+	if _, err := s.DeleteExpired(ctx); err != nil {
+		t.Fatalf("DeleteExpired() error = %v", err)
+	}
+	if revoked, _ := repo.IsTokenRevoked(ctx, active.JTI); !revoked {
+		t.Error("未过期的撤销记录不应被删除")
+	}
+	if _, err := repo.ConsumeAuthorizationCode(ctx, code.CodeHash); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("ConsumeAuthorizationCode() error = %v, want ErrNotFound", err)
+	}
+}
+
+// createTestUser 在模拟仓库中创建用户
+func createTestUser(t *testing.T, username string) *entity.User {
+	t.Helper()
+
+	user := &entity.User{Username: username, Email: username + "@example.com", Password: "x"}
+	if err := repository.NewUserRepository().Create(context.Background(), user); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	return user
+}
+
+// registerTestClient 注册测试客户端
+func registerTestClient(t *testing.T, s *OAuthService, confidential bool, grantType string) *entity.OAuthClient {
+	t.Helper()
+
+	client := &entity.OAuthClient{
+		Name:         "test",
+		GrantTypes:   grantType,
+		Scopes:       "profile email",
+		Confidential: confidential,
+	}
+	if grantType == GrantTypeAuthorizationCode {
+		client.RedirectURIs = "https://app.example.com/callback"
+	}
+	if _, err := s.RegisterClient(context.Background(), client); err != nil {
+		t.Fatalf("RegisterClient() error = %v", err)
+	}
+	return client
+}
+
+// assertOAuthError 检查错误是否为指定错误码的OAuthError
+func assertOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+
+	var oauthErr *OAuthError
+	if !errors.As(err, &oauthErr) || oauthErr.Code != code {
+		t.Fatalf("error = %v, want OAuthError %s", err, code)
+	}
+}
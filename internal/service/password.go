@@ -0,0 +1,56 @@
+package service
+
+import (
+	"gin-server-template/internal/config"
+	"gin-server-template/pkg/password"
+	"log/slog"
+)
+
+// newPasswordHasher 根据配置创建密码哈希器，读取配置失败时使用bcrypt默认参数
+func newPasswordHasher() password.PasswordHasher {
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		hasher, _ := password.New(password.Options{Algorithm: password.AlgorithmBcrypt})
+		return hasher
+	}
+
+	hasher, err := NewPasswordHasher(&cfg.Password)
+	if err != nil {
+		// 配置错误时不回退到其他算法，注册、登录等操作返回该错误
+		slog.Error("创建密码哈希器失败", "algorithm", cfg.Password.Algorithm, "error", err)
+		return unavailableHasher{err: err}
+	}
+	return hasher
+}
+
+// NewPasswordHasher 按密码哈希配置创建哈希器，算法不受支持或Argon2参数超出允许范围时返回错误
+func NewPasswordHasher(cfg *config.PasswordConfig) (password.PasswordHasher, error) {
+	return password.New(password.Options{
+		Algorithm:  cfg.Algorithm,
+		BcryptCost: cfg.BcryptCost,
+		Argon2: password.Argon2Params{
+			Memory:      cfg.Argon2.Memory,
+			Iterations:  cfg.Argon2.Iterations,
+			Parallelism: cfg.Argon2.Parallelism,
+			SaltLength:  cfg.Argon2.SaltLength,
+			KeyLength:   cfg.Argon2.KeyLength,
+		},
+	})
+}
+
+// unavailableHasher 创建失败的密码哈希器，所有操作都返回创建时的错误
+type unavailableHasher struct {
+	err error
+}
+
+func (h unavailableHasher) Hash(password string) (string, error) {
+	return "", h.err
+}
+
+func (h unavailableHasher) Verify(encoded, password string) (bool, error) {
+	return false, h.err
+}
+
+func (h unavailableHasher) NeedsRehash(encoded string) bool {
+	return false
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"gin-server-template/internal/config"
+	"gin-server-template/pkg/storage"
+	"io"
+	"log/slog"
+)
+
+// newStorage 根据配置创建对象存储，读取配置失败时使用默认的本地目录
+func newStorage() storage.Storage {
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		return storage.NewLocalStorage("uploads", "/uploads")
+	}
+
+	store, err := storage.New(storage.Options{
+		Driver:    cfg.Storage.Driver,
+		BaseURL:   cfg.Storage.BaseURL,
+		Dir:       cfg.Storage.Local.Dir,
+		Endpoint:  cfg.Storage.S3.Endpoint,
+		Region:    cfg.Storage.S3.Region,
+		Bucket:    cfg.Storage.S3.Bucket,
+		AccessKey: cfg.Storage.S3.AccessKey,
+		SecretKey: cfg.Storage.S3.SecretKey,
+		UseSSL:    cfg.Storage.S3.UseSSL,
+		PathStyle: cfg.Storage.S3.PathStyle,
+	})
+	if err != nil {
+		// 配置错误不影响服务启动，上传文件时返回该错误
+		slog.Error("创建对象存储失败", "driver", cfg.Storage.Driver, "error", err)
+		return unavailableStorage{err: err}
+	}
+	return store
+}
+
+// unavailableStorage 创建失败的对象存储，所有写操作都返回创建时的错误
+type unavailableStorage struct {
+	err error
+}
+
+func (s unavailableStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	return "", s.err
+}
+
+func (s unavailableStorage) Delete(ctx context.Context, key string) error {
+	return s.err
+}
+
+func (s unavailableStorage) Key(url string) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"gin-server-template/internal/config"
+	"gin-server-template/internal/entity"
+	"gin-server-template/internal/repository"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 令牌类型，写入typ声明；登录令牌只能访问本服务的接口，OAuth2访问令牌只能访问按权限范围授权的接口
+const (
+	TokenTypeUser   = "user"
+	TokenTypeAccess = "access"
+)
+
+// TokenService 令牌服务，负责JWT的签发、解析和撤销
+type TokenService struct {
+	oauthRepo repository.OAuthRepository
+}
+
+// NewTokenService 创建令牌服务实例
+func NewTokenService() *TokenService {
+	return &TokenService{
+		oauthRepo: repository.NewOAuthRepository(),
+	}
+}
+
+// GenerateUserToken 为登录用户签发令牌
+func (s *TokenService) GenerateUserToken(user *entity.User) (string, error) {
+	// 从配置中获取JWT密钥
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"typ":      TokenTypeUser,
+		"user_id":  user.ID,
+		"username": user.Username,
+	}
+	return s.sign(&cfg.JWT, claims, time.Hour*time.Duration(cfg.JWT.Expire)) // 从配置中获取过期时间
+}
+
+// GenerateAccessToken 为OAuth2客户端签发访问令牌，user为nil时表示客户端凭证模式
+func (s *TokenService) GenerateAccessToken(user *entity.User, client *entity.OAuthClient, scope string) (string, time.Duration, error) {
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		return "", 0, err
+	}
+
+	claims := jwt.MapClaims{
+		"typ":       TokenTypeAccess,
+		"client_id": client.ClientID,
+		"scope":     scope,
+	}
+	if user != nil {
+		claims["user_id"] = user.ID
+		claims["username"] = user.Username
+		claims["sub"] = user.Username
+	} else {
+		claims["sub"] = client.ClientID
+	}
+
+	ttl := time.Duration(cfg.OAuth.AccessTokenExpire) * time.Second
+	token, err := s.sign(&cfg.JWT, claims, ttl)
+	if err != nil {
+		return "", 0, err
+	}
+	return token, ttl, nil
+}
+
+// Parse 解析并验证令牌，已撤销的令牌视为无效
+func (s *TokenService) Parse(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	claims, err := s.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.IsRevoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.New("令牌已被撤销")
+	}
+
+	return claims, nil
+}
+
+// Revoke 根据令牌声明中的jti撤销令牌，没有jti的令牌直接忽略
+func (s *TokenService) Revoke(ctx context.Context, claims jwt.MapClaims) error {
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil
+	}
+
+	return s.oauthRepo.RevokeToken(ctx, &entity.RevokedToken{
+		JTI:       jti,
+		ExpiresAt: exp.Time,
+	})
+}
+
+// IsRevoked 检查令牌声明中的jti是否已被撤销
+func (s *TokenService) IsRevoked(ctx context.Context, claims jwt.MapClaims) (bool, error) {
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return false, nil
+	}
+	return s.oauthRepo.IsTokenRevoked(ctx, jti)
+}
+
+// sign 补充通用声明并签名令牌
+func (s *TokenService) sign(cfg *config.JWTConfig, claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims["jti"] = jti
+	claims["exp"] = now.Add(ttl).Unix()
+	claims["iat"] = now.Unix()
+	claims["iss"] = cfg.Issuer // 从配置中获取发行者
+
+	// 创建令牌并签名
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.Secret))
+}
+
+// parse 验证签名和有效期并返回令牌声明
+func (s *TokenService) parse(tokenString string) (jwt.MapClaims, error) {
+	cfg, err := config.LoadConfig("configs/config.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte(cfg.JWT.Secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, errors.New("无效的令牌")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("无效的令牌声明")
+	}
+
+	return claims, nil
+}
+
+// randomToken 生成指定字节数的随机十六进制字符串
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/pkg/response"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer 服务层使用的Tracer
+var tracer = otel.Tracer("gin-server-template/internal/service")
+
+// startSpan 为服务方法创建span
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name)
+}
+
+// endSpan 记录错误并结束span
+//
+// 参数错误、认证失败等客户端错误属于正常的业务结果，只记录错误事件，不将span标记为失败。
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+
+		var appErr *response.Error
+		if !errors.As(err, &appErr) || appErr.Status >= 500 {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
@@ -1,80 +1,162 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"gin-server-template/internal/entity"
+	"gin-server-template/internal/metrics"
 	"gin-server-template/internal/repository"
-	"golang.org/x/crypto/bcrypt"
+	"gin-server-template/pkg/logger"
+	"gin-server-template/pkg/mailer"
+	"gin-server-template/pkg/password"
+	"gin-server-template/pkg/storage"
 )
 
 // UserService 用户服务
 type UserService struct {
-	userRepo repository.UserRepository
+	userRepo        repository.UserRepository
+	emailChangeRepo repository.EmailChangeRepository
+	hasher          password.PasswordHasher
+	mailer          mailer.Mailer
+	storage         storage.Storage
 }
 
 // NewUserService 创建用户服务实例
 func NewUserService() *UserService {
 	return &UserService{
-		userRepo: repository.NewUserRepository(),
+		userRepo:        repository.NewUserRepository(),
+		emailChangeRepo: repository.NewEmailChangeRepository(),
+		hasher:          newPasswordHasher(),
+		mailer:          newMailer(),
+		storage:         newStorage(),
 	}
 }
 
 // Register 注册新用户
-func (s *UserService) Register(user *entity.User) error {
+func (s *UserService) Register(ctx context.Context, user *entity.User) (err error) {
+	ctx, span := startSpan(ctx, "UserService.Register")
+	defer func() { endSpan(span, err) }()
+
 	// 检查用户名是否已存在
-	exist, err := s.userRepo.ExistsByUsername(user.Username)
+	exist, err := s.userRepo.ExistsByUsername(ctx, user.Username)
 	if err != nil {
 		return err
 	}
 	if exist {
-		return errors.New("用户名已存在")
+		return ErrUsernameExists
 	}
 
 	// 检查邮箱是否已存在
 	if user.Email != "" {
-		exist, err = s.userRepo.ExistsByEmail(user.Email)
+		exist, err = s.userRepo.ExistsByEmail(ctx, user.Email)
 		if err != nil {
 			return err
 		}
 		if exist {
-			return errors.New("邮箱已被使用")
+			return ErrEmailExists
 		}
 	}
 
 	// 对密码进行哈希处理
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(user.Password)
 	if err != nil {
 		return err
 	}
-	user.Password = string(hashedPassword)
+	user.Password = hashedPassword
+
+	// 创建用户，并发注册时由唯一约束兜底
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		if errors.Is(err, entity.ErrDuplicate) {
+			return ErrUserExists
+		}
+		return err
+	}
 
-	// 创建用户
-	return s.userRepo.Create(user)
+	metrics.UserRegistrationsTotal.Inc()
+	return nil
 }
 
 // VerifyCredentials 验证用户凭证
-func (s *UserService) VerifyCredentials(username, password string) (*entity.User, error) {
+func (s *UserService) VerifyCredentials(ctx context.Context, username, password string) (_ *entity.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.VerifyCredentials")
+	defer func() { endSpan(span, err) }()
+
 	// 根据用户名获取用户
-	user, err := s.userRepo.GetByUsername(username)
+	user, err := s.userRepo.GetByUsername(ctx, username)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		// 不区分用户不存在和密码错误，避免泄露用户名是否已注册
+		if errors.Is(err, entity.ErrNotFound) {
+			metrics.UserLoginsTotal.WithLabelValues(metrics.LoginFailure).Inc()
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
 	}
 
-	// 验证密码
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	// 验证密码；哈希器配置错误或存储的哈希无法解析时返回原错误，按服务器错误处理并记录日志，
+	// 而不是让所有登录都表现为密码错误
+	ok, err := s.hasher.Verify(user.Password, password)
 	if err != nil {
-		return nil, errors.New("密码错误")
+		return nil, fmt.Errorf("校验用户%d的密码失败: %w", user.ID, err)
+	}
+	if !ok {
+		metrics.UserLoginsTotal.WithLabelValues(metrics.LoginFailure).Inc()
+		return nil, ErrInvalidCredentials
+	}
+	metrics.UserLoginsTotal.WithLabelValues(metrics.LoginSuccess).Inc()
+
+	// 旧算法或旧参数生成的哈希在登录成功后按当前配置升级，升级失败不影响登录
+	if s.hasher.NeedsRehash(user.Password) {
+		if hashedPassword, err := s.hasher.Hash(password); err == nil {
+			user.Password = hashedPassword
+			if err := s.userRepo.Update(ctx, user); err != nil {
+				logger.FromContext(ctx).Warn("升级用户密码哈希失败", "user_id", user.ID, "error", err)
+			}
+		}
 	}
 
 	return user, nil
 }
 
 // GetUserByID 根据ID获取用户信息
-func (s *UserService) GetUserByID(id uint) (*entity.User, error) {
-	return s.userRepo.GetByID(id)
+func (s *UserService) GetUserByID(ctx context.Context, id uint) (_ *entity.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.GetUserByID")
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateUserError(err)
+	}
+	return user, nil
 }
 
 // UpdateUser 更新用户信息
-func (s *UserService) UpdateUser(user *entity.User) error {
-	return s.userRepo.Update(user)
+func (s *UserService) UpdateUser(ctx context.Context, user *entity.User) (err error) {
+	ctx, span := startSpan(ctx, "UserService.UpdateUser")
+	defer func() { endSpan(span, err) }()
+
+	return translateUserError(s.userRepo.Update(ctx, user))
+}
+
+// UpdateUserFields 只更新用户的指定字段（数据库列名）
+func (s *UserService) UpdateUserFields(ctx context.Context, user *entity.User, fields ...string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.UpdateUserFields")
+	defer func() { endSpan(span, err) }()
+
+	return translateUserError(s.userRepo.UpdateFields(ctx, user, fields...))
+}
+
+// translateUserError 将仓库层的领域错误转换为用户服务的业务错误
+func translateUserError(err error) error {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		return ErrUserNotFound
+	case errors.Is(err, entity.ErrDuplicate):
+		// 更新时只有邮箱可能违反唯一约束
+		return ErrEmailExists
+	case errors.Is(err, entity.ErrConflict):
+		return ErrUserConflict
+	default:
+		return err
+	}
 }
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gin-server-template/internal/entity"
+	"testing"
+)
+
+func TestUpdateUserVersionConflict(t *testing.T) {
+	s := NewUserService()
+	ctx := context.Background()
+	created := createTestUser(t, "version_conflict")
+
+	// 两个请求读取到同一版本
+	first, err := s.GetUserByID(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.GetUserByID(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first.Nickname = "first"
+	if err := s.UpdateUserFields(ctx, first, "nickname"); err != nil {
+		t.Fatalf("UpdateUserFields() error = %v", err)
+	}
+	if first.Version != created.Version+1 {
+		t.Errorf("Version = %d, want %d", first.Version, created.Version+1)
+	}
+
+	// 后提交的请求基于旧版本，不能覆盖先提交的修改
+	second.Nickname = "second"
+	if err := s.UpdateUser(ctx, second); !errors.Is(err, ErrUserConflict) {
+		t.Fatalf("UpdateUser() error = %v, want ErrUserConflict", err)
+	}
+	stored, err := s.GetUserByID(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Nickname != "first" {
+		t.Errorf("Nickname = %q, want first", stored.Nickname)
+	}
+
+	// 重新读取后可以更新
+	stored.Nickname = "second"
+	if err := s.UpdateUser(ctx, stored); err != nil {
+		t.Errorf("UpdateUser() error = %v", err)
+	}
+}
+
+func TestUpdateUserNotFound(t *testing.T) {
+	s := NewUserService()
+	user := createTestUser(t, "version_deleted")
+	user.ID = 1 << 30
+
+	if err := s.UpdateUser(context.Background(), user); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("UpdateUser() error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestVerifyCredentialsHasherError(t *testing.T) {
+	s := NewUserService()
+	ctx := context.Background()
+
+	user := &entity.User{Username: "verify_creds", Email: "verify_creds@example.com", Password: "Secret123!"}
+	if err := s.Register(ctx, user); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if _, err := s.VerifyCredentials(ctx, "verify_creds", "Wrong123!"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("VerifyCredentials() error = %v, want ErrInvalidCredentials", err)
+	}
+
+	// 存储的哈希无法解析时属于服务器错误，不能表现为密码错误
+	createTestUser(t, "verify_creds_bad_hash")
+	_, err := s.VerifyCredentials(ctx, "verify_creds_bad_hash", "x")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("VerifyCredentials() error = %v, want internal error", err)
+	}
+
+	// 哈希器创建失败时同样返回原错误
+	s.hasher = unavailableHasher{err: errors.New("配置错误")}
+	_, err = s.VerifyCredentials(ctx, "verify_creds", "Secret123!")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("VerifyCredentials() error = %v, want internal error", err)
+	}
+}
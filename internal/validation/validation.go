@@ -0,0 +1,197 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"gin-server-template/pkg/i18n"
+	"gin-server-template/pkg/response"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`           // 字段的JSON路径，如 email、items[0].name
+	Rule    string `json:"rule"`            // 未通过的校验规则
+	Param   string `json:"param,omitempty"` // 规则参数，如 min=3 中的 3
+	Message string `json:"message"`         // 可读的错误信息
+}
+
+// usernamePattern 用户名只能包含字母、数字和下划线，且必须以字母开头
+var usernamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// Register 向gin的校验器注册自定义规则，并使用JSON字段名作为错误中的字段名
+func Register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("不支持的校验器类型")
+	}
+
+	v.RegisterTagNameFunc(jsonFieldName)
+
+	rules := map[string]validator.Func{
+		"username":        validateUsername,
+		"strong_password": validateStrongPassword,
+		"max_bytes":       validateMaxBytes,
+		"safe_url":        validateSafeURL,
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("注册校验规则%s失败: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// BindError 将绑定错误转换为应用错误，校验错误附带按请求语言翻译的字段级详情
+func BindError(c *gin.Context, err error) *response.Error {
+	// 请求体超过middleware.BodyLimit设置的上限
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return response.ErrTooLarge.WithCause(err)
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		// JSON语法错误、类型不匹配等
+		return response.ErrInvalidParams.WithCause(err)
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message(i18n.Locale(c), fe),
+		})
+	}
+
+	return response.ErrInvalidParams.WithCause(err).WithExtension("errors", fields)
+}
+
+// NotAllowedError 请求中包含不允许修改的字段，每个字段给出一条字段级详情
+func NotAllowedError(c *gin.Context, fields []string) *response.Error {
+	locale := i18n.Locale(c)
+	errs := make([]FieldError, 0, len(fields))
+	for _, field := range fields {
+		errs = append(errs, FieldError{
+			Field:   field,
+			Rule:    "not_allowed",
+			Message: i18n.T(locale, "validation.not_allowed", "不允许修改该字段"),
+		})
+	}
+	return response.ErrInvalidParams.WithExtension("errors", errs)
+}
+
+// jsonFieldName 取结构体字段的JSON名称
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
+
+// fieldPath 去掉命名空间中的顶层结构体名，得到字段的JSON路径
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// message 按请求语言生成字段校验失败的可读信息
+func message(locale string, fe validator.FieldError) string {
+	key := fe.Tag()
+	switch key {
+	case "min", "max":
+		// 字符串、切片和映射的min/max约束的是长度
+		switch fe.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			key += "_length"
+		}
+	}
+
+	if template, ok := i18n.Lookup(locale, "validation."+key); ok {
+		if strings.Contains(template, "%s") {
+			return fmt.Sprintf(template, fe.Param())
+		}
+		return template
+	}
+	return i18n.T(locale, "validation.default", "未通过%s校验", fe.Tag())
+}
+
+// validateUsername 校验用户名字符集
+func validateUsername(fl validator.FieldLevel) bool {
+	return usernamePattern.MatchString(fl.Field().String())
+}
+
+// validateStrongPassword 校验密码强度：至少8个字符，且包含至少三类字符
+func validateStrongPassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if utf8.RuneCountInString(password) < 8 {
+		return false
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			classes++
+		}
+	}
+	return classes >= 3
+}
+
+// validateMaxBytes 校验字符串的字节长度，max按字符计数，无法约束bcrypt等按字节截断输入的场景
+func validateMaxBytes(fl validator.FieldLevel) bool {
+	limit, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		panic(fmt.Sprintf("max_bytes参数无效: %s", fl.Param()))
+	}
+	return len(fl.Field().String()) <= limit
+}
+
+// validateSafeURL 校验头像等外部地址：只允许带主机名的http/https地址，且不能携带用户信息；
+// 本地存储生成的头像地址为本站的绝对路径（如/uploads/...），同样允许，但不允许//开头的协议相对地址
+func validateSafeURL(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" && strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//") {
+		return !strings.Contains(value, "\\")
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.User == nil
+}
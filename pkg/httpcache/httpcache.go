@@ -0,0 +1,121 @@
+// Package httpcache 实现基于ETag和Last-Modified的HTTP条件请求（RFC 9110第13节）
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag 按资源的JSON表示计算强ETag，内容不变时ETag不变
+func ETag(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// contentCodings 压缩中间件可能附加到ETag中的内容编码
+var contentCodings = []string{"gzip", "zstd", "br", "deflate"}
+
+// EncodedETag 返回按内容编码压缩后的表示对应的ETag
+//
+// 压缩后的字节与原内容不同，不能沿用原强ETag；这里在强ETag的值后附加编码名，
+// 如"abc"变为"abc-gzip"，仍是强ETag，条件请求比较时会去掉编码后缀。弱ETag原样返回。
+func EncodedETag(etag, coding string) string {
+	if etag == "" || strings.HasPrefix(etag, "W/") || !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return etag[:len(etag)-1] + "-" + coding + `"`
+}
+
+// SetHeaders 设置ETag和Last-Modified响应头，lastModified为零值时不设置Last-Modified
+func SetHeaders(w http.ResponseWriter, etag string, lastModified time.Time) {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// NotModified 判断GET/HEAD请求的缓存是否仍然有效，有效时应返回304
+//
+// 请求带有If-None-Match时只按ETag判断，否则按If-Modified-Since判断。
+func NotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return matchETag(inm, etag, true)
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		// HTTP日期只精确到秒
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// PreconditionFailed 判断修改请求的前置条件是否不满足，不满足时应返回412
+//
+// 请求带有If-Match时只按ETag判断，否则按If-Unmodified-Since判断；两者都没有时视为满足。
+func PreconditionFailed(r *http.Request, etag string, lastModified time.Time) bool {
+	if im := r.Header.Get("If-Match"); im != "" {
+		return !matchETag(im, etag, false)
+	}
+
+	if ius := r.Header.Get("If-Unmodified-Since"); ius != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ius)
+		return err == nil && lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// matchETag 判断条件请求头中的ETag列表是否包含当前ETag
+//
+// If-None-Match使用弱比较（weak为true），If-Match使用强比较，任一方为弱ETag时都不匹配。
+// 比较前去掉EncodedETag附加的编码后缀，压缩前后的表示对应同一资源版本。
+func matchETag(header, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if !weak && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+
+	etag = trimCoding(strings.TrimPrefix(etag, "W/"))
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if trimCoding(candidate) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// trimCoding 去掉EncodedETag附加的编码后缀
+func trimCoding(etag string) string {
+	for _, coding := range contentCodings {
+		if suffix := "-" + coding + `"`; strings.HasSuffix(etag, suffix) {
+			return etag[:len(etag)-len(suffix)] + `"`
+		}
+	}
+	return etag
+}
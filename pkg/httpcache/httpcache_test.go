@@ -0,0 +1,112 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestETag(t *testing.T) {
+	a, err := ETag(map[string]string{"name": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ETag(map[string]string{"name": "alice"})
+	c, _ := ETag(map[string]string{"name": "bob"})
+
+	if a != b {
+		t.Errorf("相同内容的ETag不同: %s, %s", a, b)
+	}
+	if a == c {
+		t.Error("不同内容的ETag相同")
+	}
+	if len(a) != 34 || a[0] != '"' || a[len(a)-1] != '"' {
+		t.Errorf("ETag = %s, want 32位十六进制的强ETag", a)
+	}
+}
+
+func TestEncodedETag(t *testing.T) {
+	tests := []struct {
+		etag string
+		want string
+	}{
+		{`"abc"`, `"abc-gzip"`},
+		{`W/"abc"`, `W/"abc"`},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := EncodedETag(tt.etag, "gzip"); got != tt.want {
+			t.Errorf("EncodedETag(%s) = %s, want %s", tt.etag, got, tt.want)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	etag := `"abc"`
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC)
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    bool
+	}{
+		{"ETag一致", http.MethodGet, map[string]string{"If-None-Match": `"abc"`}, true},
+		{"弱比较", http.MethodGet, map[string]string{"If-None-Match": `W/"abc"`}, true},
+		{"压缩后的ETag", http.MethodGet, map[string]string{"If-None-Match": `"abc-zstd"`}, true},
+		{"列表", http.MethodGet, map[string]string{"If-None-Match": `"x", "abc"`}, true},
+		{"通配", http.MethodHead, map[string]string{"If-None-Match": "*"}, true},
+		{"ETag不一致", http.MethodGet, map[string]string{"If-None-Match": `"xyz"`}, false},
+		{"If-None-Match优先", http.MethodGet, map[string]string{"If-None-Match": `"xyz"`, "If-Modified-Since": lastModified.Format(http.TimeFormat)}, false},
+		{"未修改", http.MethodGet, map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, true},
+		{"已修改", http.MethodGet, map[string]string{"If-Modified-Since": lastModified.Add(-time.Second).Format(http.TimeFormat)}, false},
+		{"非GET请求", http.MethodPut, map[string]string{"If-None-Match": `"abc"`}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NotModified(newRequest(tt.method, tt.headers), etag, lastModified); got != tt.want {
+				t.Errorf("NotModified = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreconditionFailed(t *testing.T) {
+	etag := `"abc"`
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		etag    string
+		headers map[string]string
+		want    bool
+	}{
+		{"没有前置条件", etag, nil, false},
+		{"ETag一致", etag, map[string]string{"If-Match": `"abc"`}, false},
+		{"压缩后的ETag", etag, map[string]string{"If-Match": `"abc-gzip"`}, false},
+		{"列表", etag, map[string]string{"If-Match": `"x", "abc"`}, false},
+		{"通配", etag, map[string]string{"If-Match": "*"}, false},
+		{"请求中的弱ETag", etag, map[string]string{"If-Match": `W/"abc"`}, true},
+		{"当前为弱ETag", `W/"abc"`, map[string]string{"If-Match": `"abc"`}, true},
+		{"ETag不一致", etag, map[string]string{"If-Match": `"xyz"`}, true},
+		{"未修改", etag, map[string]string{"If-Unmodified-Since": lastModified.Format(http.TimeFormat)}, false},
+		{"已修改", etag, map[string]string{"If-Unmodified-Since": lastModified.Add(-time.Second).Format(http.TimeFormat)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PreconditionFailed(newRequest(http.MethodPut, tt.headers), tt.etag, lastModified); got != tt.want {
+				t.Errorf("PreconditionFailed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newRequest 创建带有指定请求头的请求
+func newRequest(method string, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(method, "/", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
@@ -0,0 +1,148 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"golang.org/x/text/language"
+)
+
+// 支持的语言
+const (
+	LocaleZH = "zh"
+	LocaleEN = "en"
+)
+
+// DefaultLocale 默认语言，无法协商时使用
+const DefaultLocale = LocaleZH
+
+// localeKey gin上下文中保存语言的键
+const localeKey = "locale"
+
+var (
+	supported = []language.Tag{language.Chinese, language.English}
+	matcher   = language.NewMatcher(supported)
+
+	mu       sync.RWMutex
+	catalogs = make(map[string]map[string]string)
+)
+
+// Register 注册指定语言的消息，已存在的键会被覆盖
+func Register(locale string, messages map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	catalog, ok := catalogs[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		catalogs[locale] = catalog
+	}
+	for key, message := range messages {
+		catalog[key] = message
+	}
+}
+
+// LoadDir 从目录加载消息目录，文件名（不含扩展名）即语言，如 zh.yaml、en.yaml
+//
+// 文件中的嵌套键以点号连接，如 error: {10001: ...} 对应键 error.10001。
+func LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("目录%s中没有消息文件: %w", dir, os.ErrNotExist)
+	}
+
+	for _, file := range files {
+		v := viper.New()
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("读取消息文件%s失败: %w", file, err)
+		}
+
+		messages := make(map[string]string)
+		for _, key := range v.AllKeys() {
+			messages[key] = v.GetString(key)
+		}
+		Register(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)), messages)
+	}
+
+	return nil
+}
+
+// Lookup 查找消息，当前语言缺失时回退到默认语言
+func Lookup(locale, key string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	// viper会将键转换为小写
+	key = strings.ToLower(key)
+	if message, ok := catalogs[locale][key]; ok {
+		return message, true
+	}
+	message, ok := catalogs[DefaultLocale][key]
+	return message, ok
+}
+
+// T 翻译消息，args非空时按fmt格式化；找不到消息时返回fallback
+func T(locale, key, fallback string, args ...interface{}) string {
+	message, ok := Lookup(locale, key)
+	if !ok {
+		message = fallback
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Negotiate 根据Accept-Language协商语言
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return normalize(supported[index])
+}
+
+// Normalize 将语言标识规范化为支持的语言，不支持时返回空字符串
+func Normalize(locale string) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return ""
+	}
+	_, index, confidence := matcher.Match(tag)
+	if confidence < language.High {
+		return ""
+	}
+	return normalize(supported[index])
+}
+
+// SetLocale 保存当前请求的语言
+func SetLocale(c *gin.Context, locale string) {
+	c.Set(localeKey, locale)
+}
+
+// Locale 获取当前请求的语言
+func Locale(c *gin.Context) string {
+	if locale := c.GetString(localeKey); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// normalize 取语言标签的基础语言
+func normalize(tag language.Tag) string {
+	base, _ := tag.Base()
+	return base.String()
+}
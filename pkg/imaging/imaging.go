@@ -0,0 +1,101 @@
+// Package imaging 提供上传图片的类型识别、尺寸检查、裁剪缩放和重新编码
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/gabriel-vasile/mimetype"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// 错误定义
+var (
+	// ErrUnsupported 不支持的图片类型
+	ErrUnsupported = errors.New("不支持的图片类型")
+
+	// ErrTooLarge 图片像素数超过上限
+	ErrTooLarge = errors.New("图片尺寸过大")
+)
+
+// decoders 支持的图片类型及其解码器，键为按内容识别出的MIME类型
+var decoders = map[string]struct {
+	decode       func(r *bytes.Reader) (image.Image, error)
+	decodeConfig func(r *bytes.Reader) (image.Config, error)
+}{
+	"image/jpeg": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) },
+	},
+	"image/png": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) },
+	},
+	"image/gif": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return gif.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return gif.DecodeConfig(r) },
+	},
+	"image/webp": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) },
+	},
+}
+
+// Decode 按文件内容识别图片类型并解码，不信任客户端声明的内容类型和扩展名
+//
+// 解码前先读取图片头中的宽高，像素数超过maxPixels时返回ErrTooLarge，避免解压炸弹耗尽内存；
+// GIF只取第一帧。
+func Decode(data []byte, maxPixels int) (image.Image, error) {
+	decoder, ok := decoders[mimetype.Detect(data).String()]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	cfg, err := decoder.decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	return decoder.decode(bytes.NewReader(data))
+}
+
+// Square 从图片中央裁剪出最大的正方形并缩放为size×size
+func Square(src image.Image, size int) *image.NRGBA {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return dst
+}
+
+// Encode 重新编码图片，去除原文件中的元数据
+//
+// 不透明的图片编码为JPEG，带透明区域的编码为PNG，返回编码后的数据、内容类型和扩展名。
+func Encode(img *image.NRGBA) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if img.Opaque() {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/jpeg", ".jpg", nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/png", ".png", nil
+}
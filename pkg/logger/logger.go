@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Options 日志配置
+type Options struct {
+	Level  string // debug, info, warn, error
+	Format string // json, text
+	Output string // stdout, stderr 或文件路径
+}
+
+// contextKey 上下文中保存日志记录器的键
+type contextKey struct{}
+
+// New 根据配置创建日志记录器，返回的io.Closer用于关闭日志文件
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	level, err := parseLevel(opts.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		w      io.Writer
+		closer io.Closer = nopCloser{}
+	)
+	switch opts.Output {
+	case "", "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	default:
+		file, err := os.OpenFile(opts.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("打开日志文件失败: %w", err)
+		}
+		w, closer = file, file
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch opts.Format {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	case "text":
+		handler = slog.NewTextHandler(w, handlerOpts)
+	default:
+		closer.Close()
+		return nil, nil, fmt.Errorf("不支持的日志格式: %s", opts.Format)
+	}
+
+	return slog.New(handler), closer, nil
+}
+
+// Setup 创建日志记录器并设置为全局默认，标准库log的输出也会转到该记录器
+func Setup(opts Options) (io.Closer, error) {
+	l, closer, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(l)
+	return closer, nil
+}
+
+// WithContext 将日志记录器保存到上下文
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext 获取上下文中的日志记录器，不存在时返回全局默认记录器
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return l
+		}
+	}
+	return slog.Default()
+}
+
+// parseLevel 解析日志级别，为空时使用info
+func parseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("不支持的日志级别: %s", level)
+	}
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+	"gin-server-template/pkg/logger"
+)
+
+// LogMailer 将邮件内容写入日志而不实际发送，用于开发和测试环境
+type LogMailer struct {
+	from string
+}
+
+// NewLogMailer 创建日志邮件发送器
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+// Send 以info级别记录邮件，邮件正文可能包含确认令牌，不要在生产环境使用
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("邮件未实际发送",
+		"from", m.from,
+		"to", msg.To,
+		"subject", msg.Subject,
+		"body", msg.Body,
+	)
+	return nil
+}
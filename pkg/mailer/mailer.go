@@ -0,0 +1,61 @@
+// Package mailer 提供发送邮件的接口及其日志、SMTP实现
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// 支持的发送方式
+const (
+	DriverLog  = "log"
+	DriverSMTP = "smtp"
+)
+
+// ErrInvalidHeader 邮件头包含换行符，可能被用于注入额外的邮件头
+var ErrInvalidHeader = errors.New("邮件头不能包含换行符")
+
+// Message 纯文本邮件
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	// Send 发送邮件，ctx取消或超时时放弃发送
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Options 邮件发送配置
+type Options struct {
+	Driver   string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// New 根据配置创建邮件发送器，未配置SMTP时返回只写日志的实现
+func New(opts Options) Mailer {
+	if opts.Driver == DriverSMTP {
+		return NewSMTPMailer(opts)
+	}
+	return NewLogMailer(opts.From)
+}
+
+// validate 检查收件人和主题，防止邮件头注入
+func (m *Message) validate() error {
+	if len(m.To) == 0 {
+		return errors.New("邮件缺少收件人")
+	}
+	for _, value := range append([]string{m.Subject}, m.To...) {
+		if strings.ContainsAny(value, "\r\n") {
+			return ErrInvalidHeader
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPMailer 通过SMTP服务器发送邮件
+//
+// 服务器支持STARTTLS时自动升级为加密连接；配置了用户名时使用PLAIN认证，
+// net/smtp只允许在加密连接或本机连接上进行PLAIN认证。
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer 创建SMTP邮件发送器，未配置端口时使用587
+func NewSMTPMailer(opts Options) *SMTPMailer {
+	port := opts.Port
+	if port == 0 {
+		port = 587
+	}
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(opts.Host, strconv.Itoa(port)),
+		host:     opts.Host,
+		username: opts.Username,
+		password: opts.Password,
+		from:     opts.From,
+	}
+}
+
+// Send 发送邮件，ctx没有截止时间时最长等待30秒
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	data, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+
+	// 信封中只使用地址部分，地址格式已在build中校验
+	if err := client.Mail(envelopeAddress(m.from)); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(envelopeAddress(to)); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// build 生成邮件内容，主题按RFC 2047编码，正文使用base64编码的UTF-8纯文本
+func (m *SMTPMailer) build(msg *Message) ([]byte, error) {
+	for _, address := range append([]string{m.from}, msg.To...) {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return nil, fmt.Errorf("无效的邮件地址%q: %w", address, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", m.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	// 每行不超过76个字符
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes(), nil
+}
+
+// envelopeAddress 取出形如"名称 <user@example.com>"的地址中的邮箱部分
+func envelopeAddress(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		return parsed.Address
+	}
+	return address
+}
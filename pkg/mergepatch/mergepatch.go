@@ -0,0 +1,84 @@
+// Package mergepatch 实现JSON Merge Patch（RFC 7396）
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"reflect"
+	"sort"
+)
+
+// ContentType JSON Merge Patch的媒体类型
+const ContentType = "application/merge-patch+json"
+
+// ErrNotObject 补丁不是JSON对象
+var ErrNotObject = errors.New("merge patch必须是JSON对象")
+
+// IsContentType 判断请求的内容类型是否为JSON Merge Patch，忽略charset等参数
+func IsContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == ContentType
+}
+
+// Apply 将补丁合并到target指向的结构体上
+//
+// target的JSON表示中存在的键即为允许修改的字段：补丁中值为null的字段恢复为零值，
+// 嵌套对象按RFC 7396递归合并。补丁中target不包含的键不会被应用，按字母顺序返回，由调用方决定如何处理。
+func Apply(target interface{}, patch []byte) (unknown []string, err error) {
+	var patchDoc map[string]interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return nil, err
+	}
+	if patchDoc == nil {
+		return nil, ErrNotObject
+	}
+
+	current, err := json.Marshal(target)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(current, &doc); err != nil {
+		return nil, err
+	}
+
+	for key := range patchDoc {
+		if _, ok := doc[key]; !ok {
+			unknown = append(unknown, key)
+			delete(patchDoc, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	merged, err := json.Marshal(merge(doc, patchDoc))
+	if err != nil {
+		return nil, err
+	}
+
+	// 先清空target，被删除的字段才会恢复为零值
+	value := reflect.ValueOf(target).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	return unknown, json.Unmarshal(merged, target)
+}
+
+// merge 按RFC 7396第2节的算法合并补丁
+func merge(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = merge(targetObj[key], value)
+	}
+	return targetObj
+}
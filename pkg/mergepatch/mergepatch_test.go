@@ -0,0 +1,95 @@
+package mergepatch
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type profile struct {
+	Nickname string            `json:"nickname"`
+	Avatar   string            `json:"avatar"`
+	Settings map[string]string `json:"settings"`
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    profile
+		unknown []string
+	}{
+		{
+			name:  "修改字段",
+			patch: `{"nickname": "bob"}`,
+			want:  profile{Nickname: "bob", Avatar: "a.png", Settings: map[string]string{"theme": "dark", "lang": "zh"}},
+		},
+		{
+			name:  "null清空字段",
+			patch: `{"avatar": null}`,
+			want:  profile{Nickname: "alice", Settings: map[string]string{"theme": "dark", "lang": "zh"}},
+		},
+		{
+			name:  "嵌套对象递归合并",
+			patch: `{"settings": {"theme": null, "lang": "en"}}`,
+			want:  profile{Nickname: "alice", Avatar: "a.png", Settings: map[string]string{"lang": "en"}},
+		},
+		{
+			name:    "未知字段不应用",
+			patch:   `{"role": "admin", "id": 1, "nickname": "bob"}`,
+			want:    profile{Nickname: "bob", Avatar: "a.png", Settings: map[string]string{"theme": "dark", "lang": "zh"}},
+			unknown: []string{"id", "role"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := profile{Nickname: "alice", Avatar: "a.png", Settings: map[string]string{"theme": "dark", "lang": "zh"}}
+			unknown, err := Apply(&target, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !reflect.DeepEqual(target, tt.want) {
+				t.Errorf("target = %+v, want %+v", target, tt.want)
+			}
+			if !reflect.DeepEqual(unknown, tt.unknown) {
+				t.Errorf("unknown = %v, want %v", unknown, tt.unknown)
+			}
+		})
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	for _, patch := range []string{`null`, `["nickname"]`, `"bob"`, `{`} {
+		target := profile{Nickname: "alice"}
+		if _, err := Apply(&target, []byte(patch)); err == nil {
+			t.Errorf("Apply(%s) 应返回错误", patch)
+		}
+		if target.Nickname != "alice" {
+			t.Errorf("Apply(%s) 失败时修改了target", patch)
+		}
+	}
+
+	target := profile{}
+	if _, err := Apply(&target, []byte(`null`)); !errors.Is(err, ErrNotObject) {
+		t.Errorf("err = %v, want ErrNotObject", err)
+	}
+}
+
+func TestIsContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/merge-patch+json", true},
+		{"application/merge-patch+json; charset=utf-8", true},
+		{"Application/Merge-Patch+JSON", true},
+		{"application/json", false},
+		{"application/json-patch+json", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsContentType(tt.contentType); got != tt.want {
+			t.Errorf("IsContentType(%q) = %v, want %v", tt.contentType, got, tt.want)
+		}
+	}
+}
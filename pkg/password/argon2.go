@@ -0,0 +1,163 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2Params Argon2id参数
+type Argon2Params struct {
+	Memory      uint32 // 内存开销（KiB）
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params 默认Argon2id参数，取自RFC 9106的推荐配置
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// 允许的参数范围，哈希被篡改时避免计算耗尽服务器资源或接受空的摘要，配置的参数同样受此限制
+const (
+	maxArgon2Memory      = 1024 * 1024 // KiB，即1GiB
+	maxArgon2Iterations  = 16
+	maxArgon2Parallelism = 16
+	minArgon2SaltLength  = 8
+	minArgon2KeyLength   = 16
+	maxArgon2KeyLength   = 128
+)
+
+// Argon2idHasher Argon2id密码哈希实现
+type Argon2idHasher struct {
+	params Argon2Params
+}
+
+// NewArgon2idHasher 创建Argon2id哈希器，未设置的参数使用默认值，参数超出解析哈希时允许的范围时返回错误
+func NewArgon2idHasher(params Argon2Params) (*Argon2idHasher, error) {
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2Params.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = DefaultArgon2Params.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = DefaultArgon2Params.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultArgon2Params.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultArgon2Params.KeyLength
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return &Argon2idHasher{params: params}, nil
+}
+
+// validate 检查参数是否在允许范围内，生成哈希和解析哈希使用同一套限制，
+// 避免按配置生成的哈希在校验时被拒绝
+func (p Argon2Params) validate() error {
+	switch {
+	case p.Memory > maxArgon2Memory:
+		return fmt.Errorf("Argon2内存开销不能超过%dKiB: %d", maxArgon2Memory, p.Memory)
+	case p.Iterations < 1 || p.Iterations > maxArgon2Iterations:
+		return fmt.Errorf("Argon2迭代次数必须在1到%d之间: %d", maxArgon2Iterations, p.Iterations)
+	case p.Parallelism < 1 || p.Parallelism > maxArgon2Parallelism:
+		return fmt.Errorf("Argon2并行度必须在1到%d之间: %d", maxArgon2Parallelism, p.Parallelism)
+	case p.SaltLength < minArgon2SaltLength:
+		return fmt.Errorf("Argon2盐长度不能小于%d字节: %d", minArgon2SaltLength, p.SaltLength)
+	case p.KeyLength < minArgon2KeyLength || p.KeyLength > maxArgon2KeyLength:
+		return fmt.Errorf("Argon2摘要长度必须在%d到%d字节之间: %d", minArgon2KeyLength, maxArgon2KeyLength, p.KeyLength)
+	}
+	return nil
+}
+
+// Hash 计算密码哈希，返回PHC字符串格式
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify 校验密码
+func (h *Argon2idHasher) Verify(encoded, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	// 使用哈希中记录的参数重新计算
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash 哈希参数与当前配置不一致时需要重新计算
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, _, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return *params != h.params
+}
+
+// decodeArgon2id 解析PHC字符串格式的Argon2id哈希
+func decodeArgon2id(encoded string) (*Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return nil, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, nil, nil, ErrUnknownFormat
+	}
+	if version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("不兼容的Argon2版本: %d", version)
+	}
+
+	params := &Argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, nil, nil, ErrUnknownFormat
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, ErrUnknownFormat
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, ErrUnknownFormat
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	if err := params.validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,105 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 支持的哈希算法
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// ErrUnknownFormat 无法识别的哈希格式
+var ErrUnknownFormat = errors.New("无法识别的密码哈希格式")
+
+// PasswordHasher 密码哈希接口
+//
+// 编码后的哈希值自带算法和参数信息：bcrypt使用其标准格式（$2a$10$...），
+// Argon2id使用PHC字符串格式（$argon2id$v=19$m=65536,t=3,p=2$salt$hash）。
+type PasswordHasher interface {
+	// Hash 计算密码哈希并返回编码后的字符串
+	Hash(password string) (string, error)
+
+	// Verify 校验密码是否与编码后的哈希匹配
+	Verify(encoded, password string) (bool, error)
+
+	// NeedsRehash 检查哈希是否需要按当前算法和参数重新计算
+	NeedsRehash(encoded string) bool
+}
+
+// Options 密码哈希配置
+type Options struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+// New 根据配置创建密码哈希器，未指定算法时使用bcrypt，不支持的算法或无效的Argon2参数返回错误
+//
+// 返回的哈希器使用配置的算法生成新哈希，同时能够校验所有已支持算法生成的旧哈希，
+// 旧哈希在NeedsRehash中会被判定为需要升级。
+func New(opts Options) (PasswordHasher, error) {
+	bcryptHasher := NewBcryptHasher(opts.BcryptCost)
+	argon2Hasher, err := NewArgon2idHasher(opts.Argon2)
+	if err != nil {
+		return nil, err
+	}
+
+	var current PasswordHasher
+	switch opts.Algorithm {
+	case "", AlgorithmBcrypt:
+		current = bcryptHasher
+	case AlgorithmArgon2id:
+		current = argon2Hasher
+	default:
+		return nil, fmt.Errorf("不支持的密码哈希算法: %s", opts.Algorithm)
+	}
+
+	return &multiHasher{
+		current: current,
+		bcrypt:  bcryptHasher,
+		argon2:  argon2Hasher,
+	}, nil
+}
+
+// multiHasher 按哈希前缀分派到对应实现
+type multiHasher struct {
+	current PasswordHasher
+	bcrypt  *BcryptHasher
+	argon2  *Argon2idHasher
+}
+
+func (h *multiHasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h *multiHasher) Verify(encoded, password string) (bool, error) {
+	hasher := h.detect(encoded)
+	if hasher == nil {
+		return false, ErrUnknownFormat
+	}
+	return hasher.Verify(encoded, password)
+}
+
+func (h *multiHasher) NeedsRehash(encoded string) bool {
+	hasher := h.detect(encoded)
+	if hasher != h.current {
+		return true
+	}
+	return hasher.NeedsRehash(encoded)
+}
+
+// detect 根据编码前缀识别哈希算法
+func (h *multiHasher) detect(encoded string) PasswordHasher {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return h.argon2
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return h.bcrypt
+	default:
+		return nil
+	}
+}
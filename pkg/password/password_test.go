@@ -0,0 +1,167 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testArgon2Params 测试使用的低开销参数
+var testArgon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestNewUnknownAlgorithm(t *testing.T) {
+	if _, err := New(Options{Algorithm: "md5"}); err == nil {
+		t.Fatal("不支持的算法应返回错误")
+	}
+	for _, algorithm := range []string{"", AlgorithmBcrypt, AlgorithmArgon2id} {
+		if _, err := New(Options{Algorithm: algorithm}); err != nil {
+			t.Errorf("New(%q) error = %v", algorithm, err)
+		}
+	}
+}
+
+func TestNewInvalidArgon2Params(t *testing.T) {
+	invalid := map[string]Argon2Params{
+		"内存超出上限": {Memory: maxArgon2Memory + 1},
+		"迭代次数过大": {Iterations: maxArgon2Iterations + 1},
+		"并行度过大":  {Parallelism: maxArgon2Parallelism + 1},
+		"盐过短":    {SaltLength: minArgon2SaltLength - 1},
+		"摘要过短":   {KeyLength: minArgon2KeyLength - 1},
+		"摘要过长":   {KeyLength: maxArgon2KeyLength + 1},
+	}
+	for name, params := range invalid {
+		t.Run(name, func(t *testing.T) {
+			// 即使当前算法为bcrypt也拒绝无效参数，避免切换算法后才暴露配置错误
+			if _, err := New(Options{Algorithm: AlgorithmBcrypt, Argon2: params}); err == nil {
+				t.Errorf("New(%+v) 应返回错误", params)
+			}
+		})
+	}
+
+	// 未设置的参数使用默认值
+	if _, err := New(Options{Algorithm: AlgorithmArgon2id}); err != nil {
+		t.Errorf("New(默认参数) error = %v", err)
+	}
+}
+
+func TestDecodeArgon2id(t *testing.T) {
+	hasher, err := NewArgon2idHasher(testArgon2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := hasher.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		t.Fatalf("decodeArgon2id() error = %v", err)
+	}
+	if *params != testArgon2Params || len(salt) != 16 || len(key) != 32 {
+		t.Errorf("decodeArgon2id() = %+v, salt %d, key %d", params, len(salt), len(key))
+	}
+
+	parts := strings.Split(encoded, "$")
+	replace := func(index int, value string) string {
+		modified := append([]string(nil), parts...)
+		modified[index] = value
+		return strings.Join(modified, "$")
+	}
+
+	invalid := map[string]string{
+		"段数不足":      "$argon2id$v=19$m=1024,t=1,p=1$" + parts[4],
+		"其他算法":      replace(1, "argon2i"),
+		"版本不兼容":     replace(2, "v=16"),
+		"参数格式错误":    replace(3, "m=1024;t=1;p=1"),
+		"内存超出上限":    replace(3, "m=4194304,t=1,p=1"),
+		"迭代次数为0":    replace(3, "m=1024,t=0,p=1"),
+		"迭代次数过大":    replace(3, "m=1024,t=1000,p=1"),
+		"并行度为0":     replace(3, "m=1024,t=1,p=0"),
+		"并行度溢出":     replace(3, "m=1024,t=1,p=300"),
+		"盐不是base64": replace(4, "!!!"),
+		"盐过短":       replace(4, "YWJj"),
+		"摘要为空":      replace(5, ""),
+	}
+	for name, encoded := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, _, _, err := decodeArgon2id(encoded); err == nil {
+				t.Errorf("decodeArgon2id(%q) 应返回错误", encoded)
+			}
+			if ok, _ := hasher.Verify(encoded, "secret"); ok {
+				t.Errorf("Verify(%q) 不应通过", encoded)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	hasher, err := New(Options{Algorithm: AlgorithmArgon2id, BcryptCost: 4, Argon2: testArgon2Params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHasher := NewBcryptHasher(4)
+
+	argon2Hash, err := hasher.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash, err := bcryptHasher.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, encoded := range []string{argon2Hash, bcryptHash} {
+		if ok, err := hasher.Verify(encoded, "secret"); !ok || err != nil {
+			t.Errorf("Verify(%q, 正确密码) = %v, %v", encoded, ok, err)
+		}
+		if ok, _ := hasher.Verify(encoded, "wrong"); ok {
+			t.Errorf("Verify(%q, 错误密码) 不应通过", encoded)
+		}
+	}
+
+	if _, err := hasher.Verify("plaintext", "secret"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Verify(未知格式) error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current, err := New(Options{Algorithm: AlgorithmArgon2id, BcryptCost: 4, Argon2: testArgon2Params})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	argon2Hash, _ := current.Hash("secret")
+	bcryptHash, _ := NewBcryptHasher(4).Hash("secret")
+	stronger := testArgon2Params
+	stronger.Iterations = 2
+	strongerHasher, err := NewArgon2idHasher(stronger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldParamsHash, _ := strongerHasher.Hash("secret")
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    bool
+	}{
+		{"当前算法和参数", argon2Hash, false},
+		{"旧算法", bcryptHash, true},
+		{"参数不同", oldParamsHash, true},
+		{"无法识别", "plaintext", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := current.NeedsRehash(tt.encoded); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 改为bcrypt后，Argon2id哈希需要升级，cost变化的bcrypt哈希同样需要升级
+	bcryptCurrent, _ := New(Options{Algorithm: AlgorithmBcrypt, BcryptCost: 5})
+	if !bcryptCurrent.NeedsRehash(argon2Hash) || !bcryptCurrent.NeedsRehash(bcryptHash) {
+		t.Error("算法或cost变化后应需要重新计算哈希")
+	}
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// cleanupInterval 清理空闲令牌桶的间隔
+const cleanupInterval = time.Minute
+
+// DefaultMaxKeys 进程内存储默认最多保存的令牌桶数量
+const DefaultMaxKeys = 100000
+
+// bucket 令牌桶状态
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+	period time.Duration
+	elem   *list.Element // 在最近使用列表中的位置
+}
+
+// MemoryStore 进程内令牌桶存储
+//
+// 令牌桶数量达到上限时淘汰最久未使用的桶，避免大量不同的客户端IP耗尽内存；
+// 被淘汰的键重新出现时按装满的桶计算。
+type MemoryStore struct {
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	recent      *list.List // 按最近使用时间排列的桶，最近使用的在前
+	maxKeys     int
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+// NewMemoryStore 创建进程内令牌桶存储，maxKeys不大于0时使用DefaultMaxKeys
+func NewMemoryStore(maxKeys int) *MemoryStore {
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	return &MemoryStore{
+		buckets:     make(map[string]*bucket),
+		recent:      list.New(),
+		maxKeys:     maxKeys,
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Allow 消耗key对应桶中的一个令牌
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastCleanup) >= cleanupInterval {
+		s.cleanup(now)
+	}
+
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Period.Seconds() // 每秒补充的令牌数
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = s.add(now, key, capacity, limit.Period)
+	} else {
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+		b.last = now
+		s.recent.MoveToFront(b.elem)
+	}
+
+	result := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = secondsToDuration((capacity - b.tokens) / rate)
+
+	return result, nil
+}
+
+// add 创建装满的令牌桶，数量达到上限时先清理已装满的桶，仍然超出时淘汰最久未使用的桶
+func (s *MemoryStore) add(now time.Time, key string, capacity float64, period time.Duration) *bucket {
+	if len(s.buckets) >= s.maxKeys {
+		s.cleanup(now)
+	}
+	for len(s.buckets) >= s.maxKeys {
+		s.remove(s.recent.Back().Value.(*bucket))
+	}
+
+	b := &bucket{key: key, tokens: capacity, last: now, period: period}
+	b.elem = s.recent.PushFront(b)
+	s.buckets[key] = b
+	return b
+}
+
+// cleanup 删除已经装满的令牌桶，装满的桶与不存在的桶等价，删除后不影响限流结果
+func (s *MemoryStore) cleanup(now time.Time) {
+	s.lastCleanup = now
+
+	for _, b := range s.buckets {
+		if now.Sub(b.last) >= b.period {
+			s.remove(b)
+		}
+	}
+}
+
+// remove 删除令牌桶
+func (s *MemoryStore) remove(b *bucket) {
+	s.recent.Remove(b.elem)
+	delete(s.buckets, b.key)
+}
+
+// secondsToDuration 将秒数转换为时间间隔
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreAllow(t *testing.T) {
+	store := NewMemoryStore(0)
+	now := time.Unix(0, 0)
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Period: 10 * time.Second}
+
+	for i := 0; i < 2; i++ {
+		result, _ := store.Allow(context.Background(), "k", limit)
+		if !result.Allowed {
+			t.Fatalf("第%d个请求被拒绝", i+1)
+		}
+	}
+	result, _ := store.Allow(context.Background(), "k", limit)
+	if result.Allowed {
+		t.Fatal("超出限制的请求应被拒绝")
+	}
+	if result.RetryAfter != 5*time.Second {
+		t.Errorf("RetryAfter = %v, want 5s", result.RetryAfter)
+	}
+
+	// 按速率补充令牌
+	now = now.Add(5 * time.Second)
+	if result, _ := store.Allow(context.Background(), "k", limit); !result.Allowed {
+		t.Error("补充令牌后的请求应被允许")
+	}
+}
+
+func TestMemoryStoreMaxKeys(t *testing.T) {
+	store := NewMemoryStore(2)
+	now := time.Unix(0, 0)
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	store.Allow(context.Background(), "a", limit)
+	store.Allow(context.Background(), "b", limit)
+	// 访问a使b成为最久未使用的键
+	store.Allow(context.Background(), "a", limit)
+	store.Allow(context.Background(), "c", limit)
+
+	if len(store.buckets) != 2 {
+		t.Fatalf("len(buckets) = %d, want 2", len(store.buckets))
+	}
+	if _, ok := store.buckets["b"]; ok {
+		t.Error("应淘汰最久未使用的键b")
+	}
+	if result, _ := store.Allow(context.Background(), "a", limit); result.Allowed {
+		t.Error("未被淘汰的键a应保留计数")
+	}
+}
+
+func TestMemoryStoreCleanupBeforeEvict(t *testing.T) {
+	store := NewMemoryStore(2)
+	now := time.Unix(0, 0)
+	store.now = func() time.Time { return now }
+
+	store.Allow(context.Background(), "short", Limit{Requests: 1, Period: time.Second})
+	store.Allow(context.Background(), "long", Limit{Requests: 1, Period: time.Hour})
+
+	// short已经装满，达到上限时优先清理装满的桶，而不是淘汰仍在限流中的long
+	now = now.Add(2 * time.Second)
+	store.Allow(context.Background(), "new", Limit{Requests: 1, Period: time.Hour})
+
+	if _, ok := store.buckets["long"]; !ok {
+		t.Error("仍在限流中的键不应被淘汰")
+	}
+	if _, ok := store.buckets["short"]; ok {
+		t.Error("已装满的键应被清理")
+	}
+}
@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit 限流规则：每个周期内最多允许Requests个请求
+//
+// 使用令牌桶算法，桶容量为Requests，令牌按Requests/Period的速率匀速补充，
+// 因此允许短时间内的突发请求，长期平均速率不超过限制。
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Result 限流检查结果
+type Result struct {
+	Allowed    bool
+	Limit      int           // 桶容量
+	Remaining  int           // 剩余可用请求数
+	ResetAfter time.Duration // 桶重新装满所需时间
+	RetryAfter time.Duration // 被拒绝时需要等待的时间
+}
+
+// Store 限流状态存储接口
+//
+// 默认使用进程内存储，多实例部署时可实现基于Redis等共享存储的版本，使限流在实例间生效。
+type Store interface {
+	// Allow 消耗key对应桶中的一个令牌并返回检查结果
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
@@ -0,0 +1,59 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// Header 请求ID使用的HTTP头
+const Header = "X-Request-ID"
+
+// maxLength 客户端传入的请求ID最大长度
+const maxLength = 64
+
+// contextKey 上下文中保存请求ID的键
+type contextKey struct{}
+
+// New 生成随机的UUIDv4格式请求ID
+func New() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("生成请求ID失败: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // 版本4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122变体
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// Valid 检查客户端传入的请求ID是否可以直接使用
+//
+// 只允许字母、数字和 - _ . : 且长度不超过64，保证其可以安全地写入日志和SQL注释。
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithContext 将请求ID保存到上下文
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext 获取上下文中的请求ID，不存在时返回空字符串
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,117 @@
+package response
+
+import (
+	"gin-server-template/pkg/i18n"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 通用错误码，取值为HTTP状态码乘以100；业务错误码按模块划分区间：
+//
+//	1xxxx 用户模块
+//	2xxxx 认证模块
+const (
+	CodeSuccess      = 0
+	CodeBadRequest   = 40000
+	CodeUnauthorized = 40100
+	CodeForbidden    = 40300
+	CodeNotFound     = 40400
+	CodeConflict     = 40900
+	CodePrecondition = 41200
+	CodeTooLarge     = 41300
+	CodeUnsupported  = 41500
+	CodeTooMany      = 42900
+	CodeServerError  = 50000
+)
+
+// 通用应用错误
+var (
+	ErrInvalidParams = NewError(http.StatusBadRequest, CodeBadRequest, "无效的请求参数")
+	ErrUnauthorized  = NewError(http.StatusUnauthorized, CodeUnauthorized, "未认证的请求")
+	ErrForbidden     = NewError(http.StatusForbidden, CodeForbidden, "没有访问权限")
+	ErrNotFound      = NewError(http.StatusNotFound, CodeNotFound, "资源不存在")
+	ErrPrecondition  = NewError(http.StatusPreconditionFailed, CodePrecondition, "资源已被修改，前置条件不满足")
+	ErrTooLarge      = NewError(http.StatusRequestEntityTooLarge, CodeTooLarge, "请求体过大")
+	ErrUnsupported   = NewError(http.StatusUnsupportedMediaType, CodeUnsupported, "不支持的内容类型")
+	ErrTooMany       = NewError(http.StatusTooManyRequests, CodeTooMany, "请求过于频繁，请稍后再试")
+	ErrInternal      = NewError(http.StatusInternalServerError, CodeServerError, "服务器内部错误")
+)
+
+// Error 应用错误，携带HTTP状态码、稳定的业务错误码和可返回给客户端的安全信息
+//
+// 内部原因保存在Err中，只记录日志，不会返回给客户端；
+// Extensions中的附加信息会随错误响应一起返回。
+type Error struct {
+	Status     int
+	Code       int
+	Message    string
+	Err        error
+	Extensions map[string]interface{}
+
+	// custom 为true表示Message是调用方指定的信息，渲染时不再按业务错误码翻译
+	custom bool
+}
+
+// NewError 创建应用错误
+func NewError(status, code int, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap 返回内部原因
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is 业务错误码相同即视为同一错误，使附加了原因的副本仍能与原错误匹配
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithCause 返回附加了内部原因的副本
+func (e *Error) WithCause(err error) *Error {
+	clone := *e
+	clone.Err = err
+	return &clone
+}
+
+// WithExtension 返回附加了扩展信息的副本
+func (e *Error) WithExtension(key string, value interface{}) *Error {
+	clone := *e
+	clone.Extensions = make(map[string]interface{}, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		clone.Extensions[k] = v
+	}
+	clone.Extensions[key] = value
+	return &clone
+}
+
+// WithMessage 返回替换了客户端信息的副本
+func (e *Error) WithMessage(message string) *Error {
+	clone := *e
+	clone.Message = message
+	clone.custom = true
+	return &clone
+}
+
+// LocalizedMessage 按当前请求的语言返回错误信息，消息目录中缺失时使用Message
+func LocalizedMessage(c *gin.Context, e *Error) string {
+	if e.custom {
+		return e.Message
+	}
+	return i18n.T(i18n.Locale(c), "error."+strconv.Itoa(e.Code), e.Message)
+}
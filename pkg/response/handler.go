@@ -0,0 +1,73 @@
+package response
+
+import (
+	"errors"
+	"gin-server-template/pkg/logger"
+	"gin-server-template/pkg/requestid"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorOptions 错误响应选项
+type ErrorOptions struct {
+	// Format 默认的错误响应格式，可选值: envelope, problem
+	Format string
+
+	// ProblemTypeBase Problem的type前缀，实际type为前缀加业务错误码；为空时使用about:blank
+	ProblemTypeBase string
+}
+
+// ErrorHandler 错误处理中间件，将处理器通过c.Error记录的错误渲染为统一的响应格式
+//
+// *Error按其状态码和业务错误码返回；其他错误一律视为服务器内部错误，
+// 详细信息只记录日志，客户端只能看到按请求语言翻译后的安全信息。客户端在Accept中明确要求
+// application/problem+json时，无论默认格式如何都返回RFC 7807格式。
+func ErrorHandler(opts ErrorOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		var appErr *Error
+		if !errors.As(err, &appErr) {
+			appErr = ErrInternal.WithCause(err)
+		}
+
+		// 服务器错误和附带内部原因的错误记录详细日志
+		if appErr.Status >= 500 || appErr.Err != nil {
+			level := slog.LevelWarn
+			if appErr.Status >= 500 {
+				level = slog.LevelError
+			}
+			ctx := c.Request.Context()
+			logger.FromContext(ctx).Log(ctx, level, "请求处理失败",
+				slog.Int("code", appErr.Code),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		// 处理器已经写出响应时不再覆盖
+		if c.Writer.Written() {
+			return
+		}
+
+		if opts.Format == FormatProblem || wantsProblem(c) {
+			c.Render(appErr.Status, newProblem(c, appErr, opts.ProblemTypeBase))
+			return
+		}
+
+		resp := Response{
+			Code:      appErr.Code,
+			Message:   LocalizedMessage(c, appErr),
+			RequestID: requestid.FromContext(c.Request.Context()),
+		}
+		if len(appErr.Extensions) > 0 {
+			resp.Data = appErr.Extensions
+		}
+		c.JSON(appErr.Status, resp)
+	}
+}
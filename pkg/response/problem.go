@@ -0,0 +1,107 @@
+package response
+
+import (
+	"encoding/json"
+	"gin-server-template/pkg/requestid"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 错误响应格式
+const (
+	FormatEnvelope = "envelope" // {code, message, data}
+	FormatProblem  = "problem"  // RFC 7807 application/problem+json
+)
+
+// ContentTypeProblem RFC 7807定义的媒体类型
+const ContentTypeProblem = "application/problem+json"
+
+// Problem RFC 7807 Problem Details错误结构
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+
+	// Extensions 扩展成员，与标准成员平铺输出
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON 将扩展成员与标准成员平铺序列化
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	// 标准成员优先，扩展成员不能覆盖
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+// Render 实现gin的render.Render接口，以application/problem+json输出
+func (p Problem) Render(w http.ResponseWriter) error {
+	p.WriteContentType(w)
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// WriteContentType 写入Content-Type响应头
+func (p Problem) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentTypeProblem+"; charset=utf-8")
+}
+
+// wantsProblem 检查客户端是否在Accept中明确要求application/problem+json
+func wantsProblem(c *gin.Context) bool {
+	for _, accept := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == ContentTypeProblem {
+			return true
+		}
+	}
+	return false
+}
+
+// newProblem 根据应用错误构造Problem
+func newProblem(c *gin.Context, appErr *Error, typeBase string) Problem {
+	problemType := "about:blank"
+	if typeBase != "" {
+		problemType = typeBase + strconv.Itoa(appErr.Code)
+	}
+
+	extensions := make(map[string]interface{}, len(appErr.Extensions)+2)
+	for key, value := range appErr.Extensions {
+		extensions[key] = value
+	}
+	extensions["code"] = appErr.Code
+	if id := requestid.FromContext(c.Request.Context()); id != "" {
+		extensions["request_id"] = id
+	}
+
+	return Problem{
+		Type:       problemType,
+		Title:      http.StatusText(appErr.Status),
+		Status:     appErr.Status,
+		Detail:     LocalizedMessage(c, appErr),
+		Instance:   c.Request.URL.RequestURI(),
+		Extensions: extensions,
+	}
+}
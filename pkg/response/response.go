@@ -8,49 +8,19 @@ import (
 
 // Response 标准API响应结构
 type Response struct {
-	Code    int         `json:"code"`
+	Code    int         `json:"code"` // 业务错误码，成功时为0
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+
+	// RequestID 请求ID，仅在错误响应中返回，便于客户端反馈问题时定位日志
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // Success 返回成功响应
 func Success(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, Response{
-		Code:    http.StatusOK,
+		Code:    CodeSuccess,
 		Message: "success",
 		Data:    data,
 	})
-}
-
-// Fail 返回失败响应
-func Fail(c *gin.Context, code int, message string) {
-	c.JSON(code, Response{
-		Code:    code,
-		Message: message,
-	})
-}
-
-// BadRequest 返回400错误响应
-func BadRequest(c *gin.Context, message string) {
-	Fail(c, http.StatusBadRequest, message)
-}
-
-// Unauthorized 返回401错误响应
-func Unauthorized(c *gin.Context, message string) {
-	Fail(c, http.StatusUnauthorized, message)
-}
-
-// Forbidden 返回403错误响应
-func Forbidden(c *gin.Context, message string) {
-	Fail(c, http.StatusForbidden, message)
-}
-
-// NotFound 返回404错误响应
-func NotFound(c *gin.Context, message string) {
-	Fail(c, http.StatusNotFound, message)
-}
-
-// ServerError 返回500错误响应
-func ServerError(c *gin.Context, message string) {
-	Fail(c, http.StatusInternalServerError, message)
 }
\ No newline at end of file
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage 保存在本地目录中的对象存储，由HTTP服务以静态文件方式提供访问
+//
+// 只适合单实例部署，多实例部署时请使用S3兼容存储。
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStorage 创建本地文件系统存储，dir为保存目录，baseURL为访问地址前缀，如/uploads
+func NewLocalStorage(dir, baseURL string) *LocalStorage {
+	return &LocalStorage{dir: dir, baseURL: baseURL}
+}
+
+// Put 先写入同一目录下的临时文件再重命名，读取方不会看到写了一半的文件
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return joinURL(s.baseURL, key), nil
+}
+
+// Delete 删除对象文件，所在目录为空时一并删除
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != filepath.Clean(s.dir) {
+		// 目录不为空时删除失败，忽略即可
+		os.Remove(dir)
+	}
+	return nil
+}
+
+// Key 从访问地址还原对象键
+func (s *LocalStorage) Key(url string) (string, bool) {
+	key, ok := trimURL(s.baseURL, url)
+	return key, ok && validKey(key)
+}
+
+// path 将对象键转换为保存目录下的文件路径
+func (s *LocalStorage) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage 保存在S3兼容存储（AWS S3、MinIO、Cloudflare R2等）中的对象存储
+type S3Storage struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+// NewS3Storage 创建S3兼容存储
+//
+// 未配置区域时使用us-east-1，避免每次请求前查询存储桶所在区域。PathStyle为true时以
+// endpoint/bucket/key的形式访问，MinIO等自建服务通常需要开启。
+func NewS3Storage(opts Options) (*S3Storage, error) {
+	region := opts.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	lookup := minio.BucketLookupAuto
+	if opts.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure:       opts.UseSSL,
+		Region:       region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		endpoint := client.EndpointURL()
+		if opts.PathStyle {
+			baseURL = endpoint.Scheme + "://" + endpoint.Host + "/" + opts.Bucket
+		} else {
+			baseURL = endpoint.Scheme + "://" + opts.Bucket + "." + endpoint.Host
+		}
+	}
+
+	return &S3Storage{
+		client:  client,
+		bucket:  opts.Bucket,
+		baseURL: baseURL,
+	}, nil
+}
+
+// Put 上传对象，size为-1时使用分片上传
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return "", err
+	}
+	return joinURL(s.baseURL, key), nil
+}
+
+// Delete 删除对象，S3删除不存在的对象同样返回成功
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// Key 从访问地址还原对象键
+func (s *S3Storage) Key(url string) (string, bool) {
+	key, ok := trimURL(s.baseURL, url)
+	return key, ok && validKey(key)
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeS3 本地模拟的S3服务，只实现PutObject和DeleteObject
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	// 路径形式为/{bucket}/{key}
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err == nil && strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			data, err = decodeAWSChunked(data)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = data
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeAWSChunked 解码非HTTPS连接上使用的流式签名请求体，每块为"{长度};chunk-signature=...\r\n{数据}\r\n"
+func decodeAWSChunked(body []byte) ([]byte, error) {
+	var data []byte
+	for {
+		header, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		if !ok {
+			return nil, errors.New("无效的分块")
+		}
+		sizeHex, _, _ := strings.Cut(string(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || int64(len(rest)) < size+2 {
+			return nil, errors.New("无效的分块长度")
+		}
+		if size == 0 {
+			return data, nil
+		}
+		data = append(data, rest[:size]...)
+		body = rest[size+2:]
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s, err := NewS3Storage(Options{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Bucket:    "avatars",
+		AccessKey: "access",
+		SecretKey: "secret",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	url, err := s.Put(ctx, "avatars/1/abc/256.jpg", bytes.NewReader([]byte("image")), 5, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if want := server.URL + "/avatars/avatars/1/abc/256.jpg"; url != want {
+		t.Errorf("url = %s, want %s", url, want)
+	}
+	if got := string(fake.objects["/avatars/avatars/1/abc/256.jpg"]); got != "image" {
+		t.Errorf("对象内容 = %q, want image", got)
+	}
+	if got := fake.types["/avatars/avatars/1/abc/256.jpg"]; got != "image/jpeg" {
+		t.Errorf("Content-Type = %q, want image/jpeg", got)
+	}
+
+	key, ok := s.Key(url)
+	if !ok || key != "avatars/1/abc/256.jpg" {
+		t.Errorf("Key() = %q, %v", key, ok)
+	}
+	if _, ok := s.Key("https://evil.example/avatars/avatars/1/abc/256.jpg"); ok {
+		t.Error("其他地址不应还原出对象键")
+	}
+	if _, ok := s.Key(server.URL + "/avatars/../secret"); ok {
+		t.Error("包含..的地址不应还原出对象键")
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists := fake.objects["/avatars/avatars/1/abc/256.jpg"]; exists {
+		t.Error("对象应已删除")
+	}
+
+	for _, invalid := range []string{"", "/abs", "a/../b", `a\\b`} {
+		if _, err := s.Put(ctx, invalid, bytes.NewReader(nil), 0, "image/jpeg"); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", invalid, err)
+		}
+		if err := s.Delete(ctx, invalid); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Delete(%q) error = %v, want ErrInvalidKey", invalid, err)
+		}
+	}
+}
+
+func TestS3StorageBaseURL(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"路径形式", Options{Endpoint: "minio.local:9000", Bucket: "avatars", PathStyle: true}, "http://minio.local:9000/avatars"},
+		{"虚拟主机形式", Options{Endpoint: "s3.amazonaws.com", Bucket: "avatars", UseSSL: true}, "https://avatars.s3.amazonaws.com"},
+		{"自定义地址", Options{Endpoint: "s3.amazonaws.com", Bucket: "avatars", BaseURL: "https://cdn.example.com/"}, "https://cdn.example.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewS3Storage(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.baseURL != tt.want {
+				t.Errorf("baseURL = %s, want %s", s.baseURL, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package storage 提供对象存储接口及其本地文件系统、S3兼容实现
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// 支持的存储方式
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// ErrInvalidKey 对象键为空、是绝对路径或包含..等路径穿越
+var ErrInvalidKey = errors.New("无效的对象键")
+
+// Storage 对象存储接口，对象键使用/分隔，如avatars/1/abc/256.jpg
+type Storage interface {
+	// Put 保存对象，已存在时覆盖，返回对象的访问地址
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error)
+
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+
+	// Key 从访问地址还原对象键，地址不属于该存储时返回false
+	Key(url string) (string, bool)
+}
+
+// Options 存储配置
+type Options struct {
+	Driver  string
+	BaseURL string // 对象访问地址前缀，为空时S3按端点和存储桶生成
+
+	// 本地文件系统
+	Dir string
+
+	// S3兼容存储
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PathStyle bool
+}
+
+// New 根据配置创建对象存储，默认使用本地文件系统
+func New(opts Options) (Storage, error) {
+	if opts.Driver == DriverS3 {
+		return NewS3Storage(opts)
+	}
+	return NewLocalStorage(opts.Dir, opts.BaseURL), nil
+}
+
+// joinURL 拼接访问地址前缀和对象键
+func joinURL(baseURL, key string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + key
+}
+
+// trimURL 去掉访问地址前缀得到对象键
+func trimURL(baseURL, url string) (string, bool) {
+	prefix := strings.TrimSuffix(baseURL, "/") + "/"
+	if !strings.HasPrefix(url, prefix) || len(url) == len(prefix) {
+		return "", false
+	}
+	return url[len(prefix):], true
+}
+
+// validKey 检查对象键是否为安全的相对路径
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
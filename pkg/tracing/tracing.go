@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Options 链路追踪配置
+type Options struct {
+	Enabled     bool
+	ServiceName string
+	Endpoint    string  // OTLP/HTTP接收端地址，如localhost:4318
+	Insecure    bool    // 是否使用HTTP而非HTTPS
+	SampleRatio float64 // 采样比例，取值0~1
+}
+
+// Setup 初始化全局TracerProvider和W3C Trace Context传播器
+//
+// 未启用时只设置传播器，上游传入的追踪上下文仍会向下游传递，但不会记录和导出span。
+// 返回的函数用于在退出前刷新并关闭导出器。
+func Setup(opts Options) (func(context.Context) error, error) {
+	if !opts.Enabled {
+		setPropagator()
+		return func(context.Context) error { return nil }, nil
+	}
+
+	clientOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return SetupWithExporter(opts, exporter), nil
+}
+
+// SetupWithExporter 使用指定的导出器初始化全局TracerProvider，便于使用内存导出器等替代实现
+func SetupWithExporter(opts Options, exporter sdktrace.SpanExporter) func(context.Context) error {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(opts.ServiceName),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	setPropagator()
+
+	return provider.Shutdown
+}
+
+// setPropagator 设置W3C Trace Context和Baggage传播器
+func setPropagator() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}